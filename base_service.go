@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 )
@@ -11,15 +12,32 @@ import (
 type BaseService struct {
 	name         string
 	deps         []string
+	optionalDeps []string
+	weakDeps     []string
+	afterDeps    []string
 	priority     ServicePriority
+	stopTimeout  time.Duration
 	stateMachine *StateMachine
 
+	// 依赖注入导出值
+	providedType reflect.Type
+	provideFunc  func() any
+
 	// 生命周期回调
 	initFunc   func(context.Context) error
 	startFunc  func(context.Context) error
 	stopFunc   func(context.Context) error
 	updateFunc func(context.Context, interface{}) error
 
+	// 状态变更观察者
+	observers      []stateObserver
+	nextObserverID uint64
+	lastTransition time.Time
+
+	// 日志器，logger 为服务自身设置的日志器，groupLogger 为从服务组继承的日志器
+	logger      Logger
+	groupLogger Logger
+
 	mu     sync.RWMutex
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -51,6 +69,8 @@ func NewBaseService(name string, deps []string, opts ...ServiceOption) *BaseServ
 		name:     name,
 		deps:     deps,
 		priority: PriorityNormal, // 默认优先级
+
+		lastTransition: time.Now(),
 	}
 
 	// 应用选项
@@ -62,9 +82,59 @@ func NewBaseService(name string, deps []string, opts ...ServiceOption) *BaseServ
 	return bs
 }
 
-// handleStateChange 处理状态变更
+// handleStateChange 处理状态变更，通知所有观察者
 func (bs *BaseService) handleStateChange(from, to ServiceState) {
-	// 这里可以添加日志记录或监控
+	now := time.Now()
+
+	bs.mu.Lock()
+	var inState time.Duration
+	if !bs.lastTransition.IsZero() {
+		inState = now.Sub(bs.lastTransition)
+	}
+	bs.lastTransition = now
+	observers := append([]stateObserver(nil), bs.observers...)
+	bs.mu.Unlock()
+
+	change := StateChange{
+		Service:  bs.name,
+		From:     from,
+		To:       to,
+		Time:     now,
+		Duration: inState,
+	}
+	for _, observer := range observers {
+		observer.fn(change)
+	}
+}
+
+// stateObserver 已注册的状态变更观察者
+type stateObserver struct {
+	id uint64
+	fn StateObserver
+}
+
+// AddStateObserver 实现 StateNotifier 接口，注册状态变更观察者，返回注销该观察者的函数
+func (bs *BaseService) AddStateObserver(observer StateObserver) func() {
+	if observer == nil {
+		return func() {}
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.nextObserverID++
+	id := bs.nextObserverID
+	bs.observers = append(bs.observers, stateObserver{id: id, fn: observer})
+
+	return func() {
+		bs.mu.Lock()
+		defer bs.mu.Unlock()
+		for i, o := range bs.observers {
+			if o.id == id {
+				bs.observers = append(bs.observers[:i:i], bs.observers[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 // 实现 Service 接口
@@ -80,6 +150,21 @@ func (bs *BaseService) Dependencies() []string {
 	return bs.deps
 }
 
+// OptionalDependencies 实现 SoftDependencies 接口
+func (bs *BaseService) OptionalDependencies() []string {
+	return bs.optionalDeps
+}
+
+// WeakDependencies 实现 SoftDependencies 接口
+func (bs *BaseService) WeakDependencies() []string {
+	return bs.weakDeps
+}
+
+// AfterDependencies 实现 SoftDependencies 接口
+func (bs *BaseService) AfterDependencies() []string {
+	return bs.afterDeps
+}
+
 // Init 初始化服务
 func (bs *BaseService) Init(ctx context.Context) error {
 	// 先转换到初始化状态
@@ -91,10 +176,13 @@ func (bs *BaseService) Init(ctx context.Context) error {
 	if bs.initFunc != nil {
 		if err := bs.initFunc(ctx); err != nil {
 			bs.stateMachine.TransitionTo(StateError)
+			bs.GetLogger().Debug("Service init function failed",
+				"error", err)
 			return fmt.Errorf("init function failed: %w", err)
 		}
 	}
 
+	bs.GetLogger().Debug("Service init completed")
 	return nil
 }
 
@@ -116,12 +204,18 @@ func (bs *BaseService) Start(ctx context.Context) error {
 	if bs.startFunc != nil {
 		if err := bs.startFunc(ctx); err != nil {
 			bs.stateMachine.TransitionTo(StateError)
+			bs.GetLogger().Debug("Service start function failed",
+				"error", err)
 			return fmt.Errorf("start function failed: %w", err)
 		}
 	}
 
 	// 最后转换到 Running 状态
-	return bs.stateMachine.TransitionTo(StateRunning)
+	if err := bs.stateMachine.TransitionTo(StateRunning); err != nil {
+		return err
+	}
+	bs.GetLogger().Debug("Service start completed")
+	return nil
 }
 
 // Stop 停止服务
@@ -133,17 +227,28 @@ func (bs *BaseService) Stop(ctx context.Context) error {
 	if bs.stopFunc != nil {
 		if err := bs.stopFunc(ctx); err != nil {
 			bs.stateMachine.TransitionTo(StateError)
+			bs.GetLogger().Debug("Service stop function failed",
+				"error", err)
 			return err
 		}
 	}
 
-	return bs.stateMachine.TransitionTo(StateStopped)
+	if err := bs.stateMachine.TransitionTo(StateStopped); err != nil {
+		return err
+	}
+	bs.GetLogger().Debug("Service stop completed")
+	return nil
 }
 
 // Update 更新服务配置
 func (bs *BaseService) Update(ctx context.Context, config interface{}) error {
 	if bs.updateFunc != nil {
-		return bs.updateFunc(ctx, config)
+		if err := bs.updateFunc(ctx, config); err != nil {
+			bs.GetLogger().Debug("Service update function failed",
+				"error", err)
+			return err
+		}
+		bs.GetLogger().Debug("Service configuration updated")
 	}
 	return nil
 }
@@ -204,6 +309,52 @@ func WithPriority(priority ServicePriority) ServiceOption {
 	}
 }
 
+// WithOptionalDependencies 设置可选依赖，依赖不存在时忽略
+func WithOptionalDependencies(names ...string) ServiceOption {
+	return func(bs *BaseService) {
+		bs.optionalDeps = append(bs.optionalDeps, names...)
+	}
+}
+
+// WithWeakDependencies 设置弱依赖，只影响启动顺序，失败不阻塞本服务启动
+func WithWeakDependencies(names ...string) ServiceOption {
+	return func(bs *BaseService) {
+		bs.weakDeps = append(bs.weakDeps, names...)
+	}
+}
+
+// WithAfter 设置启动顺序提示，指定服务存在时本服务在其之后启动
+func WithAfter(names ...string) ServiceOption {
+	return func(bs *BaseService) {
+		bs.afterDeps = append(bs.afterDeps, names...)
+	}
+}
+
+// WithStopTimeout 设置服务的停止期限
+func WithStopTimeout(timeout time.Duration) ServiceOption {
+	return func(bs *BaseService) {
+		bs.stopTimeout = timeout
+	}
+}
+
+// StopTimeout 实现 StopTimeouter 接口
+func (bs *BaseService) StopTimeout() time.Duration {
+	return bs.stopTimeout
+}
+
+// ProvidedType 实现 Provider 接口
+func (bs *BaseService) ProvidedType() reflect.Type {
+	return bs.providedType
+}
+
+// Provide 实现 Provider 接口
+func (bs *BaseService) Provide() any {
+	if bs.provideFunc == nil {
+		return nil
+	}
+	return bs.provideFunc()
+}
+
 // Priority 实现 Service 接口
 func (bs *BaseService) Priority() ServicePriority {
 	return bs.priority
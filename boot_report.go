@@ -0,0 +1,446 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BootReport 服务组一次启动的耗时报告
+type BootReport struct {
+	Started  time.Time
+	Duration time.Duration // 服务组启动总耗时
+	Err      error         // 启动失败时的错误
+	Levels   int
+
+	// 参与本次启动的服务，按开始处理的时间排序
+	Services []BootEntry
+	// 决定总耗时的依赖链，从最先就绪的服务到最后就绪的服务
+	// 链上每个服务都是下一个服务在依赖图中最晚就绪的依赖
+	CriticalPath []string
+}
+
+// BootEntry 单个服务的启动耗时
+type BootEntry struct {
+	Name         string
+	Level        int
+	Dependencies []string      // 影响启动顺序且参与本次启动的依赖
+	Offset       time.Duration // 开始处理该服务的时间，相对 BootReport.Started
+	Ready        time.Duration // Start 步骤完成的时间，相对 BootReport.Started，未完成时为 0
+	Wait         time.Duration // 等待依赖就绪的耗时
+	Init         time.Duration // Init 步骤耗时，无需初始化时为 0
+	Start        time.Duration // Start 步骤耗时
+	Phases       []BootPhase   // 各步骤的时间线
+	Blocking     time.Duration // 其他依赖都已就绪后，依赖方仍需等待该服务的最长时间
+	Critical     bool          // 是否位于关键路径上
+	Err          error
+}
+
+// Active 返回服务自身的启动耗时（Init 与 Start 之和）
+func (e BootEntry) Active() time.Duration {
+	return e.Init + e.Start
+}
+
+// BootPhase 服务启动过程中的一个步骤
+type BootPhase struct {
+	Name     string        // Wait、Init 或 Start
+	Offset   time.Duration // 相对 BootReport.Started
+	Duration time.Duration
+}
+
+// Blame 返回按自身启动耗时从长到短排序的服务，类似 systemd-analyze blame
+func (r *BootReport) Blame() []BootEntry {
+	entries := append([]BootEntry(nil), r.Services...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Active() > entries[j].Active()
+	})
+	return entries
+}
+
+// Blocking 返回阻塞过依赖方的服务，按阻塞时长从长到短排序
+func (r *BootReport) Blocking() []BootEntry {
+	var entries []BootEntry
+	for _, e := range r.Services {
+		if e.Blocking > 0 {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Blocking > entries[j].Blocking
+	})
+	return entries
+}
+
+// Entry 返回指定服务的启动耗时
+func (r *BootReport) Entry(name string) (BootEntry, bool) {
+	for _, e := range r.Services {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return BootEntry{}, false
+}
+
+// String 返回文本格式的报告
+func (r *BootReport) String() string {
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	return buf.String()
+}
+
+// WriteText 以文本格式输出报告，包含 blame、critical chain 和阻塞最久的服务
+func (r *BootReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	status := "finished"
+	if r.Err != nil {
+		status = "failed"
+	}
+	fmt.Fprintf(&b, "Startup %s in %s (%d services, %d levels)\n",
+		status, r.Duration.Round(time.Microsecond), len(r.Services), r.Levels)
+
+	b.WriteString("\nBlame:\n")
+	for _, e := range r.Blame() {
+		fmt.Fprintf(&b, "%12s %s", e.Active().Round(time.Microsecond), e.Name)
+		if e.Err != nil {
+			fmt.Fprintf(&b, " (failed: %v)", e.Err)
+		}
+		b.WriteByte('\n')
+	}
+
+	if len(r.CriticalPath) > 0 {
+		b.WriteString("\nCritical chain:\n")
+		for i := len(r.CriticalPath) - 1; i >= 0; i-- {
+			e, _ := r.Entry(r.CriticalPath[i])
+			depth := len(r.CriticalPath) - 1 - i
+			if depth > 0 {
+				b.WriteString(strings.Repeat("  ", depth-1))
+				b.WriteString("└─")
+			}
+			fmt.Fprintf(&b, "%s @%s +%s\n", e.Name,
+				e.Ready.Round(time.Microsecond), e.Active().Round(time.Microsecond))
+		}
+	}
+
+	if blocking := r.Blocking(); len(blocking) > 0 {
+		b.WriteString("\nBlocking:\n")
+		for _, e := range blocking {
+			fmt.Fprintf(&b, "%12s %s\n", e.Blocking.Round(time.Microsecond), e.Name)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bootReportJSON 报告的 JSON 格式，时长以字符串形式输出
+type bootReportJSON struct {
+	Started      time.Time       `json:"started"`
+	Duration     string          `json:"duration"`
+	Error        string          `json:"error,omitempty"`
+	Levels       int             `json:"levels"`
+	CriticalPath []string        `json:"criticalPath"`
+	Services     []bootEntryJSON `json:"services"`
+}
+
+// bootEntryJSON 单个服务的 JSON 格式
+type bootEntryJSON struct {
+	Name         string   `json:"name"`
+	Level        int      `json:"level"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Offset       string   `json:"offset"`
+	Ready        string   `json:"ready,omitempty"`
+	Wait         string   `json:"wait"`
+	Init         string   `json:"init"`
+	Start        string   `json:"start"`
+	Active       string   `json:"active"`
+	Blocking     string   `json:"blocking,omitempty"`
+	Critical     bool     `json:"critical"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (r *BootReport) MarshalJSON() ([]byte, error) {
+	v := bootReportJSON{
+		Started:      r.Started,
+		Duration:     r.Duration.String(),
+		Levels:       r.Levels,
+		CriticalPath: r.CriticalPath,
+		Services:     make([]bootEntryJSON, 0, len(r.Services)),
+	}
+	if v.CriticalPath == nil {
+		v.CriticalPath = []string{}
+	}
+	if r.Err != nil {
+		v.Error = r.Err.Error()
+	}
+	for _, e := range r.Services {
+		entry := bootEntryJSON{
+			Name:         e.Name,
+			Level:        e.Level,
+			Dependencies: e.Dependencies,
+			Offset:       e.Offset.String(),
+			Wait:         e.Wait.String(),
+			Init:         e.Init.String(),
+			Start:        e.Start.String(),
+			Active:       e.Active().String(),
+			Critical:     e.Critical,
+		}
+		if e.Ready > 0 {
+			entry.Ready = e.Ready.String()
+		}
+		if e.Blocking > 0 {
+			entry.Blocking = e.Blocking.String()
+		}
+		if e.Err != nil {
+			entry.Error = e.Err.Error()
+		}
+		v.Services = append(v.Services, entry)
+	}
+	return json.Marshal(v)
+}
+
+// WriteJSON 以 JSON 格式输出报告
+func (r *BootReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// chromeTraceEvent Chrome trace-event 格式中的一个事件
+type chromeTraceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteChromeTrace 以 Chrome trace-event 格式输出报告，可在 chrome://tracing 或 Perfetto 中查看
+// 每个服务占一行，关键路径上的步骤归入 critical 分类
+func (r *BootReport) WriteChromeTrace(w io.Writer) error {
+	events := []chromeTraceEvent{{
+		Name: "process_name",
+		Ph:   "M",
+		Pid:  1,
+		Args: map[string]interface{}{"name": "ServiceGroup.Start"},
+	}}
+	for i, e := range r.Services {
+		tid := i + 1
+		events = append(events, chromeTraceEvent{
+			Name: "thread_name",
+			Ph:   "M",
+			Pid:  1,
+			Tid:  tid,
+			Args: map[string]interface{}{"name": e.Name},
+		})
+
+		cat := "boot"
+		if e.Critical {
+			cat = "boot,critical"
+		}
+		for _, p := range e.Phases {
+			args := map[string]interface{}{
+				"service": e.Name,
+				"level":   e.Level,
+			}
+			if p.Name == "Wait" && len(e.Dependencies) > 0 {
+				args["dependencies"] = e.Dependencies
+			}
+			if e.Err != nil {
+				args["error"] = e.Err.Error()
+			}
+			events = append(events, chromeTraceEvent{
+				Name: p.Name,
+				Cat:  cat,
+				Ph:   "X",
+				Ts:   p.Offset.Microseconds(),
+				Dur:  p.Duration.Microseconds(),
+				Pid:  1,
+				Tid:  tid,
+				Args: args,
+			})
+		}
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
+
+// bootRecorder 记录服务组一次启动中各服务的步骤耗时
+type bootRecorder struct {
+	began   time.Time
+	levels  int
+	mu      sync.Mutex
+	entries map[string]*BootEntry
+	order   map[string]int // 服务在启动层级中的顺序，用于稳定排序
+}
+
+// newBootRecorder 根据启动层级创建记录器
+func newBootRecorder(began time.Time, levels [][]string, graph *DependencyGraph) *bootRecorder {
+	rec := &bootRecorder{
+		began:   began,
+		levels:  len(levels),
+		entries: make(map[string]*BootEntry),
+		order:   make(map[string]int),
+	}
+
+	inBoot := make(map[string]bool)
+	for _, level := range levels {
+		for _, name := range level {
+			inBoot[name] = true
+		}
+	}
+	for i, level := range levels {
+		for _, name := range level {
+			entry := &BootEntry{Name: name, Level: i}
+			if node, ok := graph.GetNode(name); ok {
+				for _, dep := range node.orderDeps() {
+					if inBoot[dep] {
+						entry.Dependencies = append(entry.Dependencies, dep)
+					}
+				}
+			}
+			rec.order[name] = len(rec.order)
+			rec.entries[name] = entry
+		}
+	}
+	return rec
+}
+
+// bootKey 上下文中保存启动记录器的键
+type bootKey struct{}
+
+// contextWithBoot 返回携带启动记录器的上下文
+func contextWithBoot(ctx context.Context, rec *bootRecorder) context.Context {
+	return context.WithValue(ctx, bootKey{}, rec)
+}
+
+// bootFromContext 返回上下文中的启动记录器，不在启动过程中时返回 nil
+func bootFromContext(ctx context.Context) *bootRecorder {
+	rec, _ := ctx.Value(bootKey{}).(*bootRecorder)
+	return rec
+}
+
+// recordPhase 记录一个步骤，只记录 Wait、Init 和 Start
+func (rec *bootRecorder) recordPhase(name, phase string, began time.Time, d time.Duration) {
+	if rec == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	e, ok := rec.entries[name]
+	if !ok {
+		return
+	}
+	offset := began.Sub(rec.began)
+	if len(e.Phases) == 0 || offset < e.Offset {
+		e.Offset = offset
+	}
+	e.Phases = append(e.Phases, BootPhase{Name: phase, Offset: offset, Duration: d})
+
+	switch phase {
+	case "Wait":
+		e.Wait += d
+	case string(EventInit):
+		e.Init += d
+	case string(EventStart):
+		e.Start += d
+		e.Ready = offset + d
+	}
+}
+
+// finish 记录服务的启动结果
+func (rec *bootRecorder) finish(name string, err error) {
+	if rec == nil || err == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if e, ok := rec.entries[name]; ok {
+		e.Err = err
+		e.Ready = 0
+	}
+}
+
+// report 生成启动报告，计算关键路径和阻塞时长
+func (rec *bootRecorder) report(duration time.Duration, err error) *BootReport {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	r := &BootReport{
+		Started:  rec.began,
+		Duration: duration,
+		Err:      err,
+		Levels:   rec.levels,
+	}
+
+	// 关键路径：从最后就绪的服务开始，沿最晚就绪的依赖回溯
+	latestDep := func(e *BootEntry) *BootEntry {
+		var latest *BootEntry
+		for _, dep := range e.Dependencies {
+			if d := rec.entries[dep]; d.Ready > 0 && (latest == nil || d.Ready > latest.Ready) {
+				latest = d
+			}
+		}
+		return latest
+	}
+	var last *BootEntry
+	for _, e := range rec.entries {
+		if e.Ready > 0 && (last == nil || e.Ready > last.Ready || (e.Ready == last.Ready && e.Name < last.Name)) {
+			last = e
+		}
+	}
+	for e := last; e != nil; e = latestDep(e) {
+		e.Critical = true
+		r.CriticalPath = append([]string{e.Name}, r.CriticalPath...)
+	}
+
+	// 阻塞时长：依赖方的其他依赖都已就绪（且最晚的依赖已开始处理）之后，仍在等待最晚就绪的依赖的时间
+	for _, e := range rec.entries {
+		latest := latestDep(e)
+		if latest == nil {
+			continue
+		}
+		since := latest.Offset
+		for _, dep := range e.Dependencies {
+			if d := rec.entries[dep]; d != latest && d.Ready > since {
+				since = d.Ready
+			}
+		}
+		if blocked := latest.Ready - since; blocked > latest.Blocking {
+			latest.Blocking = blocked
+		}
+	}
+
+	r.Services = make([]BootEntry, 0, len(rec.entries))
+	for _, e := range rec.entries {
+		entry := *e
+		entry.Dependencies = append([]string(nil), e.Dependencies...)
+		entry.Phases = append([]BootPhase(nil), e.Phases...)
+		r.Services = append(r.Services, entry)
+	}
+	sort.Slice(r.Services, func(i, j int) bool {
+		a, b := r.Services[i], r.Services[j]
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		return rec.order[a.Name] < rec.order[b.Name]
+	})
+	return r
+}
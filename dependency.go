@@ -2,15 +2,112 @@ package service
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 	"sync"
 )
 
+// DependencyKind 依赖类型
+type DependencyKind int
+
+const (
+	// DependencyRequired 必需依赖：必须存在，并且在依赖方启动前处于运行状态
+	DependencyRequired DependencyKind = iota
+	// DependencyOptional 可选依赖：不存在时忽略，存在时与必需依赖相同
+	DependencyOptional
+	// DependencyWeak 弱依赖：存在时先于依赖方启动，但其失败不会阻塞依赖方
+	DependencyWeak
+	// DependencyAfter 顺序提示：两者都存在时依赖方在其之后启动，不建立其他关系
+	DependencyAfter
+)
+
+// String 实现 Stringer 接口
+func (k DependencyKind) String() string {
+	switch k {
+	case DependencyRequired:
+		return "required"
+	case DependencyOptional:
+		return "optional"
+	case DependencyWeak:
+		return "weak"
+	case DependencyAfter:
+		return "after"
+	default:
+		return fmt.Sprintf("DependencyKind(%d)", int(k))
+	}
+}
+
 // ServiceNode 表示依赖图中的服务节点
 type ServiceNode struct {
 	Name     string
 	Priority ServicePriority
-	Deps     []string
+	Deps     []string // 必需依赖
+	Optional []string // 可选依赖
+	Weak     []string // 弱依赖
+	After    []string // 启动顺序提示
+
+	// 依赖注入
+	Provides reflect.Type            // 服务导出值的类型
+	Injects  map[string]reflect.Type // 需要从依赖注入的值类型，键为依赖服务名
+}
+
+// orderDeps 返回影响启动顺序的所有依赖
+func (n *ServiceNode) orderDeps() []string {
+	deps := make([]string, 0, len(n.Deps)+len(n.Optional)+len(n.Weak)+len(n.After))
+	deps = append(deps, n.Deps...)
+	deps = append(deps, n.Optional...)
+	deps = append(deps, n.Weak...)
+	return append(deps, n.After...)
+}
+
+// hardDeps 返回必须处于运行状态才能启动的依赖（必需依赖和可选依赖）
+func (n *ServiceNode) hardDeps() []string {
+	deps := make([]string, 0, len(n.Deps)+len(n.Optional))
+	deps = append(deps, n.Deps...)
+	return append(deps, n.Optional...)
+}
+
+// depsOf 返回指定类型的依赖，未指定类型时返回所有影响启动顺序的依赖
+func (n *ServiceNode) depsOf(kinds []DependencyKind) []string {
+	if len(kinds) == 0 {
+		return n.orderDeps()
+	}
+	var deps []string
+	for _, kind := range kinds {
+		switch kind {
+		case DependencyRequired:
+			deps = append(deps, n.Deps...)
+		case DependencyOptional:
+			deps = append(deps, n.Optional...)
+		case DependencyWeak:
+			deps = append(deps, n.Weak...)
+		case DependencyAfter:
+			deps = append(deps, n.After...)
+		}
+	}
+	return deps
+}
+
+// DependencyKind 返回指定依赖的类型
+func (n *ServiceNode) DependencyKind(dep string) (DependencyKind, bool) {
+	kinds := []struct {
+		kind DependencyKind
+		deps []string
+	}{
+		{DependencyRequired, n.Deps},
+		{DependencyOptional, n.Optional},
+		{DependencyWeak, n.Weak},
+		{DependencyAfter, n.After},
+	}
+	for _, k := range kinds {
+		for _, d := range k.deps {
+			if d == dep {
+				return k.kind, true
+			}
+		}
+	}
+	return 0, false
 }
 
 // DependencyGraph 管理服务依赖关系
@@ -26,8 +123,30 @@ func NewDependencyGraph() *DependencyGraph {
 	}
 }
 
+// AddNodeOption 添加服务节点的选项
+type AddNodeOption func(*addNodeOptions)
+
+// addNodeOptions 添加服务节点的配置
+type addNodeOptions struct {
+	allowMissing bool
+}
+
+// AllowMissingDependencies 允许必需依赖尚未添加到依赖图中，适用于不按依赖顺序注册服务的场景
+// 缺失的必需依赖改由 Validate 报告
+func AllowMissingDependencies() AddNodeOption {
+	return func(o *addNodeOptions) {
+		o.allowMissing = true
+	}
+}
+
 // AddNode 添加服务节点
-func (dg *DependencyGraph) AddNode(node *ServiceNode) error {
+// 默认要求所有必需依赖已在依赖图中，缺失时返回 ErrDependencyFailed 错误，可通过 AllowMissingDependencies 放宽
+func (dg *DependencyGraph) AddNode(node *ServiceNode, opts ...AddNodeOption) error {
+	var o addNodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	dg.mu.Lock()
 	defer dg.mu.Unlock()
 
@@ -39,8 +158,29 @@ func (dg *DependencyGraph) AddNode(node *ServiceNode) error {
 		}
 	}
 
+	// 检查必需依赖是否存在
+	if !o.allowMissing {
+		var chains []string
+		for _, dep := range node.Deps {
+			if _, exists := dg.nodes[dep]; !exists {
+				chains = append(chains, node.Name+" -> "+dep)
+			}
+		}
+		if len(chains) > 0 {
+			return &ServiceError{
+				Code:    ErrDependencyFailed,
+				Message: "missing required dependency: " + strings.Join(chains, "; "),
+			}
+		}
+	}
+
 	// 检查是否会形成循环依赖
-	if err := dg.checkCyclicDependency(node.Name, node.Deps); err != nil {
+	if err := dg.checkCyclicDependency(node.Name, node.orderDeps()); err != nil {
+		return err
+	}
+
+	// 检查依赖注入的提供者与类型
+	if err := dg.checkInjections(node); err != nil {
 		return err
 	}
 
@@ -48,121 +188,220 @@ func (dg *DependencyGraph) AddNode(node *ServiceNode) error {
 	return nil
 }
 
-// checkCyclicDependency 检查是否存在循环依赖
+// RemoveNode 移除服务节点
+func (dg *DependencyGraph) RemoveNode(name string) error {
+	dg.mu.Lock()
+	defer dg.mu.Unlock()
+
+	if _, exists := dg.nodes[name]; !exists {
+		return &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("service %s not found in dependency graph", name),
+		}
+	}
+	delete(dg.nodes, name)
+	return nil
+}
+
+// checkCyclicDependency 检查加入依赖为 deps 的服务后是否会形成循环依赖，形成时返回带有完整路径的错误
 func (dg *DependencyGraph) checkCyclicDependency(service string, deps []string) error {
 	visited := make(map[string]bool)
-	var check func(string) error
+	var path []string
 
-	check = func(current string) error {
+	var reaches func(string) bool
+	reaches = func(current string) bool {
+		path = append(path, current)
 		if current == service {
-			return &ServiceError{
-				Code:    ErrDependencyFailed,
-				Message: "cyclic dependency detected",
-			}
-		}
-		if visited[current] {
-			return nil
+			return true
 		}
-		visited[current] = true
-
-		node, exists := dg.nodes[current]
-		if exists {
-			for _, dep := range node.Deps {
-				if err := check(dep); err != nil {
-					return err
+		if !visited[current] {
+			visited[current] = true
+			if node, exists := dg.nodes[current]; exists {
+				for _, dep := range node.orderDeps() {
+					if reaches(dep) {
+						return true
+					}
 				}
 			}
 		}
-		return nil
+		path = path[:len(path)-1]
+		return false
 	}
 
 	for _, dep := range deps {
-		if err := check(dep); err != nil {
-			return err
+		if reaches(dep) {
+			return cycleError(append([]string{service}, path...))
 		}
 	}
 	return nil
 }
 
+// cycleError 返回描述循环依赖的错误
+func cycleError(cycle []string) error {
+	return &ServiceError{
+		Code:    ErrDependencyFailed,
+		Message: "cyclic dependency detected: " + strings.Join(cycle, " -> "),
+		Cycle:   cycle,
+	}
+}
+
 // GetStartOrder 获取服务启动顺序
+// 依赖总是排在依赖方之前；按依赖层级依次排列，同一层级内按优先级、名称排序，结果是确定的
 func (dg *DependencyGraph) GetStartOrder() ([]string, error) {
+	levels, err := dg.GetStartLevels()
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	for _, level := range levels {
+		order = append(order, level...)
+	}
+	return order, nil
+}
+
+// GetStartLevels 按依赖层级分组返回启动顺序
+// 每一层中的服务只依赖于之前层级中的服务，因此同一层的服务可以并行启动
+// 服务的层级等于它到没有依赖的服务的最长路径长度，同一层级内按优先级、名称排序
+func (dg *DependencyGraph) GetStartLevels() ([][]string, error) {
 	dg.mu.RLock()
 	defer dg.mu.RUnlock()
 
-	visited := make(map[string]bool)
-	temp := make(map[string]bool)
-	var order []*ServiceNode
+	return dg.topoLevels()
+}
 
-	var visit func(string) error
-	visit = func(name string) error {
-		if temp[name] {
-			return &ServiceError{
-				Code:    ErrDependencyFailed,
-				Message: fmt.Sprintf("cyclic dependency detected involving %s", name),
+// topoLevels 使用 Kahn 算法计算依赖层级，调用方需持有读锁
+// 每一轮取出所有剩余依赖数为 0 的服务作为一层，不存在的依赖由 Validate 报告，这里忽略
+func (dg *DependencyGraph) topoLevels() ([][]string, error) {
+	pending := make(map[string]int, len(dg.nodes))
+	dependents := make(map[string][]string, len(dg.nodes))
+	for name, node := range dg.nodes {
+		seen := make(map[string]bool)
+		for _, dep := range node.orderDeps() {
+			// 同一依赖可能以多种类型声明，只计一次
+			if _, exists := dg.nodes[dep]; !exists || seen[dep] {
+				continue
 			}
+			seen[dep] = true
+			pending[name]++
+			dependents[dep] = append(dependents[dep], name)
 		}
-		if visited[name] {
-			return nil
+	}
+
+	var current []string
+	for name := range dg.nodes {
+		if pending[name] == 0 {
+			current = append(current, name)
 		}
-		temp[name] = true
+	}
 
-		node := dg.nodes[name]
-		for _, dep := range node.Deps {
-			if err := visit(dep); err != nil {
-				return err
+	var levels [][]string
+	visited := 0
+	for len(current) > 0 {
+		dg.sortLevel(current)
+		levels = append(levels, current)
+		visited += len(current)
+
+		var next []string
+		for _, name := range current {
+			for _, d := range dependents[name] {
+				pending[d]--
+				if pending[d] == 0 {
+					next = append(next, d)
+				}
 			}
 		}
-		temp[name] = false
-		visited[name] = true
-		order = append(order, node)
-		return nil
+		current = next
 	}
 
-	// 遍历所有节点
-	for name := range dg.nodes {
-		if !visited[name] {
-			if err := visit(name); err != nil {
-				return nil, err
-			}
+	if visited < len(dg.nodes) {
+		// 剩余的服务中至少存在一个循环
+		if cycles := dg.cycles(); len(cycles) > 0 {
+			return nil, cycleError(cycles[0])
+		}
+		return nil, &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: "cyclic dependency detected",
 		}
 	}
+	return levels, nil
+}
 
-	// 按层级和优先级排序
-	levels := make(map[int][]*ServiceNode)
-	maxLevel := 0
+// sortLevel 同层级服务按优先级、名称排序
+func (dg *DependencyGraph) sortLevel(level []string) {
+	sort.Slice(level, func(i, j int) bool {
+		pi, pj := dg.nodes[level[i]].Priority, dg.nodes[level[j]].Priority
+		if pi != pj {
+			return pi < pj
+		}
+		return level[i] < level[j]
+	})
+}
 
-	// 计算每个服务的层级
-	for i, node := range order {
-		level := 0
+// dependents 返回每个服务的直接依赖方，edges 决定计入哪些类型的依赖
+func (dg *DependencyGraph) dependents(edges func(*ServiceNode) []string) map[string][]string {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	result := make(map[string][]string, len(dg.nodes))
+	for name, node := range dg.nodes {
+		for _, dep := range edges(node) {
+			result[dep] = append(result[dep], name)
+		}
+	}
+	return result
+}
+
+// Validate 检查依赖图的完整性，报告缺失的必需依赖及完整的依赖链
+func (dg *DependencyGraph) Validate() error {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	// 从没有被任何服务必需依赖的根节点开始遍历
+	dependedOn := make(map[string]bool)
+	for _, node := range dg.nodes {
 		for _, dep := range node.Deps {
-			for j := 0; j < i; j++ {
-				if order[j].Name == dep && j > level {
-					level = j
-				}
-			}
+			dependedOn[dep] = true
 		}
-		if level > maxLevel {
-			maxLevel = level
+	}
+	roots := make([]string, 0, len(dg.nodes))
+	for name := range dg.nodes {
+		if !dependedOn[name] {
+			roots = append(roots, name)
 		}
-		levels[level] = append(levels[level], node)
 	}
+	sort.Strings(roots)
+
+	var chains []string
+	visited := make(map[string]bool)
+
+	var walk func(name string, path []string)
+	walk = func(name string, path []string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
 
-	// 对每个层级内的服务按优先级排序
-	result := make([]string, 0, len(order))
-	for level := 0; level <= maxLevel; level++ {
-		services := levels[level]
-		if len(services) > 0 {
-			// 同层级服务按优先级排序
-			sort.Slice(services, func(i, j int) bool {
-				return services[i].Priority < services[j].Priority
-			})
-			for _, s := range services {
-				result = append(result, s.Name)
+		path = append(path[:len(path):len(path)], name)
+		for _, dep := range dg.nodes[name].Deps {
+			if _, exists := dg.nodes[dep]; !exists {
+				chains = append(chains, strings.Join(append(path[:len(path):len(path)], dep), " -> "))
+				continue
 			}
+			walk(dep, path)
 		}
 	}
+	for _, root := range roots {
+		walk(root, nil)
+	}
 
-	return result, nil
+	if len(chains) > 0 {
+		return &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: "missing required dependency: " + strings.Join(chains, "; "),
+		}
+	}
+	return nil
 }
 
 // GetDependencies 获取服务的依赖
@@ -177,6 +416,112 @@ func (dg *DependencyGraph) GetDependencies(name string) ([]string, bool) {
 	return node.Deps, true
 }
 
+// Dependents 获取直接依赖指定服务的服务，按名称排序
+// kinds 指定计入的依赖类型，未指定时计入所有影响启动顺序的依赖
+func (dg *DependencyGraph) Dependents(name string, kinds ...DependencyKind) []string {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	var result []string
+	for _, n := range dg.sortedNames() {
+		if contains(dg.nodes[n].depsOf(kinds), name) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// TransitiveDependencies 获取指定服务直接或间接依赖的所有服务，按名称排序，不包含不在依赖图中的依赖
+// kinds 指定沿哪些类型的依赖遍历，未指定时沿所有影响启动顺序的依赖遍历
+func (dg *DependencyGraph) TransitiveDependencies(name string, kinds ...DependencyKind) []string {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	return dg.closure(name, func(n *ServiceNode) []string {
+		return n.depsOf(kinds)
+	})
+}
+
+// TransitiveDependents 获取直接或间接依赖指定服务的所有服务，按名称排序
+// kinds 指定沿哪些类型的依赖遍历，未指定时沿所有影响启动顺序的依赖遍历
+func (dg *DependencyGraph) TransitiveDependents(name string, kinds ...DependencyKind) []string {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	dependents := make(map[string][]string, len(dg.nodes))
+	for n, node := range dg.nodes {
+		for _, dep := range node.depsOf(kinds) {
+			dependents[dep] = append(dependents[dep], n)
+		}
+	}
+
+	seen := map[string]bool{name: true}
+	queue := []string{name}
+	var result []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[current] {
+			if !seen[d] {
+				seen[d] = true
+				result = append(result, d)
+				queue = append(queue, d)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Subgraph 返回只包含指定服务的新依赖图，指向子图之外的依赖会被移除，不存在的服务会被忽略
+// 子图中的节点是副本，修改子图不会影响原依赖图
+func (dg *DependencyGraph) Subgraph(names ...string) *DependencyGraph {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, exists := dg.nodes[name]; exists {
+			keep[name] = true
+		}
+	}
+	inside := func(deps []string) []string {
+		var result []string
+		for _, dep := range deps {
+			if keep[dep] {
+				result = append(result, dep)
+			}
+		}
+		return result
+	}
+
+	sub := NewDependencyGraph()
+	for name := range keep {
+		node := *dg.nodes[name]
+		node.Deps = inside(node.Deps)
+		node.Optional = inside(node.Optional)
+		node.Weak = inside(node.Weak)
+		node.After = inside(node.After)
+		sub.nodes[name] = &node
+	}
+	return sub
+}
+
+// Nodes 获取所有服务节点的副本，按名称排序
+func (dg *DependencyGraph) Nodes() []ServiceNode {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	nodes := make([]ServiceNode, 0, len(dg.nodes))
+	for _, node := range dg.nodes {
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
 // GetNode 获取服务节点
 func (dg *DependencyGraph) GetNode(name string) (*ServiceNode, bool) {
 	dg.mu.RLock()
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphAnalysis 依赖图的分析结果
+type GraphAnalysis struct {
+	// 循环依赖，每组相互依赖的服务报告一个最短循环，从其中名称最小的服务开始，首尾为同一个服务，例如 [api cache auth api]
+	Cycles [][]string
+	// 声明了但不在依赖图中的依赖，只有缺失的必需依赖会阻止服务启动
+	Missing []MissingDependency
+	// 无法启动的服务：位于循环中，或通过必需、可选依赖直接或间接依赖缺失的必需依赖或循环中的服务
+	Unreachable []string
+	// 每个服务直接或间接依赖的所有服务（所有影响启动顺序的依赖类型），按名称排序
+	Closure map[string][]string
+}
+
+// MissingDependency 缺失的依赖
+type MissingDependency struct {
+	Service    string
+	Dependency string
+	Kind       DependencyKind
+}
+
+// String 实现 Stringer 接口
+func (m MissingDependency) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", m.Service, m.Dependency, m.Kind)
+}
+
+// Err 依赖图存在循环依赖或缺失的必需依赖时返回错误，错误的 Cycle 为第一个循环
+func (a *GraphAnalysis) Err() error {
+	var problems []string
+	for _, cycle := range a.Cycles {
+		problems = append(problems, "cycle "+strings.Join(cycle, " -> "))
+	}
+	for _, m := range a.Missing {
+		if m.Kind == DependencyRequired {
+			problems = append(problems, "missing "+m.Dependency+" required by "+m.Service)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	err := &ServiceError{
+		Code:    ErrDependencyFailed,
+		Message: "invalid dependency graph: " + strings.Join(problems, "; "),
+	}
+	if len(a.Cycles) > 0 {
+		err.Cycle = a.Cycles[0]
+	}
+	return err
+}
+
+// Analyze 分析依赖图，报告所有循环依赖、缺失的依赖、无法启动的服务以及每个服务的传递依赖
+// 与 Validate 和 GetStartLevels 不同，Analyze 不会在遇到第一个问题时停止
+func (dg *DependencyGraph) Analyze() *GraphAnalysis {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	a := &GraphAnalysis{
+		Cycles:  dg.cycles(),
+		Closure: make(map[string][]string, len(dg.nodes)),
+	}
+
+	names := dg.sortedNames()
+	for _, name := range names {
+		node := dg.nodes[name]
+		kinds := []struct {
+			kind DependencyKind
+			deps []string
+		}{
+			{DependencyRequired, node.Deps},
+			{DependencyOptional, node.Optional},
+			{DependencyWeak, node.Weak},
+			{DependencyAfter, node.After},
+		}
+		for _, k := range kinds {
+			for _, dep := range k.deps {
+				if _, exists := dg.nodes[dep]; !exists {
+					a.Missing = append(a.Missing, MissingDependency{Service: name, Dependency: dep, Kind: k.kind})
+				}
+			}
+		}
+		a.Closure[name] = dg.closure(name, (*ServiceNode).orderDeps)
+	}
+
+	// 循环中的服务和缺失必需依赖的服务无法启动，并沿必需、可选依赖传递给依赖方
+	blocked := make(map[string]bool)
+	for _, cycle := range a.Cycles {
+		for _, name := range cycle {
+			blocked[name] = true
+		}
+	}
+	for _, m := range a.Missing {
+		if m.Kind == DependencyRequired {
+			blocked[m.Service] = true
+		}
+	}
+	for _, name := range names {
+		if blocked[name] {
+			continue
+		}
+		for _, dep := range dg.closure(name, (*ServiceNode).hardDeps) {
+			if blocked[dep] {
+				blocked[name] = true
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		if blocked[name] {
+			a.Unreachable = append(a.Unreachable, name)
+		}
+	}
+	return a
+}
+
+// sortedNames 返回按名称排序的所有服务，调用方需持有读锁
+func (dg *DependencyGraph) sortedNames() []string {
+	names := make([]string, 0, len(dg.nodes))
+	for name := range dg.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closure 返回服务沿 edges 直接或间接到达的所有服务（不含自身，除非位于循环中），按名称排序，调用方需持有读锁
+func (dg *DependencyGraph) closure(name string, edges func(*ServiceNode) []string) []string {
+	seen := make(map[string]bool)
+	queue := []string{name}
+	var result []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		node, exists := dg.nodes[current]
+		if !exists {
+			continue
+		}
+		for _, dep := range edges(node) {
+			if _, exists := dg.nodes[dep]; !exists || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			result = append(result, dep)
+			queue = append(queue, dep)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// cycles 返回依赖图中的循环依赖，调用方需持有读锁
+// 每组相互依赖的服务（非平凡强连通分量）只报告一个最短循环，从其中名称最小的服务开始，沿依赖声明的顺序搜索，结果是确定的。
+// 不枚举所有基本循环，后者的数量和耗时都可能随服务数指数增长
+func (dg *DependencyGraph) cycles() [][]string {
+	_, components := dg.components()
+
+	result := make([][]string, 0, len(components))
+	for _, members := range components {
+		start := members[0]
+		inComponent := make(map[string]bool, len(members))
+		for _, name := range members {
+			inComponent[name] = true
+		}
+
+		// 在分量内广度优先搜索回到起点的最短路径
+		parent := map[string]string{}
+		queue := []string{start}
+		var last string
+	search:
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, dep := range dg.nodes[current].orderDeps() {
+				if !inComponent[dep] {
+					continue
+				}
+				if dep == start {
+					last = current
+					break search
+				}
+				if _, seen := parent[dep]; seen {
+					continue
+				}
+				parent[dep] = current
+				queue = append(queue, dep)
+			}
+		}
+
+		cycle := []string{start}
+		for name := last; name != start; name = parent[name] {
+			cycle = append(cycle, name)
+		}
+		// 反转为依赖方指向依赖的顺序
+		for l, r := 1, len(cycle)-1; l < r; l, r = l+1, r-1 {
+			cycle[l], cycle[r] = cycle[r], cycle[l]
+		}
+		result = append(result, append(cycle, start))
+	}
+	return result
+}
+
+// components 使用 Tarjan 算法求包含循环的强连通分量，调用方需持有读锁
+// 返回每个位于循环中的服务所属分量的下标，以及各分量的成员（按名称排序，分量按第一个成员排序）
+func (dg *DependencyGraph) components() (map[string]int, [][]string) {
+	names := dg.sortedNames()
+
+	index := make(map[string]int, len(names))
+	low := make(map[string]int, len(names))
+	onStack := make(map[string]bool)
+	var stack []string
+	var found [][]string
+
+	var connect func(string)
+	connect = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, dep := range dg.nodes[name].orderDeps() {
+			if _, exists := dg.nodes[dep]; !exists {
+				continue
+			}
+			if dep == name {
+				selfLoop = true
+			}
+			if _, visited := index[dep]; !visited {
+				connect(dep)
+				low[name] = min(low[name], low[dep])
+			} else if onStack[dep] {
+				low[name] = min(low[name], index[dep])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+		var members []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			members = append(members, top)
+			if top == name {
+				break
+			}
+		}
+		if len(members) > 1 || selfLoop {
+			sort.Strings(members)
+			found = append(found, members)
+		}
+	}
+	for _, name := range names {
+		if _, visited := index[name]; !visited {
+			connect(name)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i][0] < found[j][0]
+	})
+	component := make(map[string]int)
+	for i, members := range found {
+		for _, name := range members {
+			component[name] = i
+		}
+	}
+	return component, found
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStartLevelsCycle(t *testing.T) {
+	// AddNode 会拒绝形成循环的节点，直接写入以验证 GetStartLevels 的报告
+	dg := NewDependencyGraph()
+	for _, node := range []*ServiceNode{
+		{Name: "api", Deps: []string{"cache"}},
+		{Name: "cache", Weak: []string{"auth"}},
+		{Name: "auth", After: []string{"api"}},
+		{Name: "db"},
+	} {
+		dg.nodes[node.Name] = node
+	}
+
+	_, err := dg.GetStartLevels()
+	se, ok := err.(*ServiceError)
+	if !ok || se.Code != ErrDependencyFailed {
+		t.Fatalf("GetStartLevels error = %v, want ErrDependencyFailed", err)
+	}
+	if want := []string{"api", "cache", "auth", "api"}; !reflect.DeepEqual(se.Cycle, want) {
+		t.Fatalf("Cycle = %v, want %v", se.Cycle, want)
+	}
+}
+
+// 无环但路径数随规模指数增长的图，循环检测必须是线性的
+func TestAnalyzeLadderGraph(t *testing.T) {
+	dg := NewDependencyGraph()
+	for i := 0; i < 200; i++ {
+		node := &ServiceNode{Name: fmt.Sprintf("s%03d", i)}
+		if i >= 2 {
+			node.Deps = []string{fmt.Sprintf("s%03d", i-1), fmt.Sprintf("s%03d", i-2)}
+		}
+		if err := dg.AddNode(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := dg.Analyze()
+	if len(a.Cycles) != 0 || a.Err() != nil {
+		t.Fatalf("unexpected problems: %v", a.Err())
+	}
+	if edges := dg.cycleEdges(); len(edges) != 0 {
+		t.Fatalf("unexpected cycle edges: %v", edges)
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// randomDAG 生成随机的无环依赖图，边只从编号大的服务指向编号小的服务，依赖类型随机
+// 部分服务会声明不存在的可选依赖和顺序提示，它们不应影响启动顺序
+func randomDAG(r *rand.Rand) []*ServiceNode {
+	priorities := []ServicePriority{PriorityHighest, PriorityHigh, PriorityNormal, PriorityLow, PriorityLowest}
+	n := 1 + r.Intn(40)
+	nodes := make([]*ServiceNode, n)
+	for i := range nodes {
+		node := &ServiceNode{
+			Name:     fmt.Sprintf("svc-%02d", r.Intn(1000)*100+i),
+			Priority: priorities[r.Intn(len(priorities))],
+		}
+		for j := 0; j < i; j++ {
+			if r.Intn(4) != 0 {
+				continue
+			}
+			dep := nodes[j].Name
+			switch r.Intn(4) {
+			case 0:
+				node.Deps = append(node.Deps, dep)
+			case 1:
+				node.Optional = append(node.Optional, dep)
+			case 2:
+				node.Weak = append(node.Weak, dep)
+			default:
+				node.After = append(node.After, dep)
+			}
+		}
+		if r.Intn(5) == 0 {
+			node.Optional = append(node.Optional, "absent-optional")
+		}
+		if r.Intn(5) == 0 {
+			node.After = append(node.After, "absent-after")
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// buildGraph 按指定顺序添加节点
+func buildGraph(t *testing.T, nodes []*ServiceNode, order []int) *DependencyGraph {
+	t.Helper()
+	dg := NewDependencyGraph()
+	for _, i := range order {
+		if err := dg.AddNode(nodes[i], AllowMissingDependencies()); err != nil {
+			t.Fatalf("AddNode(%s): %v", nodes[i].Name, err)
+		}
+	}
+	return dg
+}
+
+// depths 计算每个服务到没有依赖的服务的最长依赖路径长度
+func depths(nodes []*ServiceNode) map[string]int {
+	byName := make(map[string]*ServiceNode, len(nodes))
+	for _, node := range nodes {
+		byName[node.Name] = node
+	}
+	memo := make(map[string]int, len(nodes))
+	var depth func(string) int
+	depth = func(name string) int {
+		if d, ok := memo[name]; ok {
+			return d
+		}
+		d := 0
+		for _, dep := range byName[name].orderDeps() {
+			if _, exists := byName[dep]; exists {
+				d = max(d, depth(dep)+1)
+			}
+		}
+		memo[name] = d
+		return d
+	}
+	for _, node := range nodes {
+		depth(node.Name)
+	}
+	return memo
+}
+
+func TestStartLevelsProperties(t *testing.T) {
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		nodes := randomDAG(r)
+
+		inOrder := make([]int, len(nodes))
+		for i := range inOrder {
+			inOrder[i] = i
+		}
+		dg := buildGraph(t, nodes, inOrder)
+		levels, err := dg.GetStartLevels()
+		if err != nil {
+			t.Errorf("seed %d: GetStartLevels: %v", seed, err)
+			return false
+		}
+
+		// 每个服务恰好出现一次，层级等于最长依赖路径长度
+		want := depths(nodes)
+		level := make(map[string]int, len(nodes))
+		for i, names := range levels {
+			for _, name := range names {
+				if _, dup := level[name]; dup {
+					t.Errorf("seed %d: %s appears twice", seed, name)
+					return false
+				}
+				level[name] = i
+				if want[name] != i {
+					t.Errorf("seed %d: %s at level %d, longest path depth is %d", seed, name, i, want[name])
+					return false
+				}
+			}
+		}
+		if len(level) != len(nodes) {
+			t.Errorf("seed %d: %d services in levels, want %d", seed, len(level), len(nodes))
+			return false
+		}
+
+		// 所有类型的依赖都先于依赖方启动
+		order, err := dg.GetStartOrder()
+		if err != nil {
+			t.Errorf("seed %d: GetStartOrder: %v", seed, err)
+			return false
+		}
+		position := make(map[string]int, len(order))
+		for i, name := range order {
+			position[name] = i
+		}
+		for _, node := range nodes {
+			for _, dep := range node.orderDeps() {
+				if _, exists := position[dep]; !exists {
+					continue
+				}
+				if position[dep] >= position[node.Name] || level[dep] >= level[node.Name] {
+					t.Errorf("seed %d: dependency %s does not precede %s", seed, dep, node.Name)
+					return false
+				}
+			}
+		}
+
+		// 同一层级内按优先级、名称排序
+		byName := make(map[string]*ServiceNode, len(nodes))
+		for _, node := range nodes {
+			byName[node.Name] = node
+		}
+		for _, names := range levels {
+			for i := 1; i < len(names); i++ {
+				a, b := byName[names[i-1]], byName[names[i]]
+				if a.Priority > b.Priority || (a.Priority == b.Priority && a.Name >= b.Name) {
+					t.Errorf("seed %d: %s (priority %d) sorted before %s (priority %d)",
+						seed, a.Name, a.Priority, b.Name, b.Priority)
+					return false
+				}
+			}
+		}
+
+		// 结果与添加顺序和调用次数无关
+		shuffled := r.Perm(len(nodes))
+		for i := 0; i < 3; i++ {
+			again, err := buildGraph(t, nodes, shuffled).GetStartLevels()
+			if err != nil || !reflect.DeepEqual(again, levels) {
+				t.Errorf("seed %d: levels differ across runs: %v vs %v (%v)", seed, again, levels, err)
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sort"
+)
+
+// historyEntry 历史记录中的事件，seq 为全局递增序号，用于合并多个服务的历史
+type historyEntry struct {
+	seq   uint64
+	event ServiceEvent
+}
+
+// eventRing 固定容量的事件环形缓冲区
+type eventRing struct {
+	entries []historyEntry
+	next    int
+	full    bool
+}
+
+// newEventRing 创建指定容量的环形缓冲区
+func newEventRing(size int) *eventRing {
+	return &eventRing{entries: make([]historyEntry, size)}
+}
+
+// add 追加事件，容量已满时覆盖最旧的事件
+func (r *eventRing) add(entry historyEntry) {
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot 按从旧到新的顺序返回缓冲区中的事件
+func (r *eventRing) snapshot() []historyEntry {
+	if !r.full {
+		return append([]historyEntry(nil), r.entries[:r.next]...)
+	}
+	result := make([]historyEntry, 0, len(r.entries))
+	result = append(result, r.entries[r.next:]...)
+	return append(result, r.entries[:r.next]...)
+}
+
+// eventHistory 全局与按服务划分的事件历史
+type eventHistory struct {
+	seq        uint64
+	global     *eventRing
+	services   map[string]*eventRing
+	perService int
+	health     map[string]healthResult // 每个服务最近一次记录的健康检查结果
+}
+
+// healthResult 健康检查事件的结果摘要
+type healthResult struct {
+	ready, live, failed bool
+}
+
+// newEventHistory 创建事件历史，容量为 0 的部分不记录
+func newEventHistory(globalSize, perService int) *eventHistory {
+	h := &eventHistory{
+		services:   make(map[string]*eventRing),
+		perService: perService,
+		health:     make(map[string]healthResult),
+	}
+	if globalSize > 0 {
+		h.global = newEventRing(globalSize)
+	}
+	return h
+}
+
+// record 记录事件
+// 健康检查事件只在结果（就绪、存活、是否出错）变化时记录，避免频繁的探针挤掉生命周期事件
+func (h *eventHistory) record(event ServiceEvent) {
+	if event.EventType == EventHealthCheck {
+		ready, _ := event.Metadata[MetadataReady].(bool)
+		live, _ := event.Metadata[MetadataLive].(bool)
+		result := healthResult{ready: ready, live: live, failed: event.Error != nil}
+		if last, ok := h.health[event.ServiceName]; ok && last == result {
+			return
+		}
+		h.health[event.ServiceName] = result
+	}
+
+	h.seq++
+	entry := historyEntry{seq: h.seq, event: event}
+
+	if h.global != nil {
+		h.global.add(entry)
+	}
+	if h.perService > 0 && event.ServiceName != "" {
+		ring, ok := h.services[event.ServiceName]
+		if !ok {
+			ring = newEventRing(h.perService)
+			h.services[event.ServiceName] = ring
+		}
+		ring.add(entry)
+	}
+}
+
+// query 按从旧到新的顺序返回满足过滤条件的事件
+// 指定了服务名且启用了按服务记录时从各服务的缓冲区读取，可以查到已被全局缓冲区淘汰的事件
+func (h *eventHistory) query(filter EventFilter) []ServiceEvent {
+	var entries []historyEntry
+	if len(filter.Services) > 0 && h.perService > 0 {
+		seen := make(map[string]bool, len(filter.Services))
+		for _, name := range filter.Services {
+			if ring, ok := h.services[name]; ok && !seen[name] {
+				seen[name] = true
+				entries = append(entries, ring.snapshot()...)
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].seq < entries[j].seq
+		})
+	} else if h.global != nil {
+		entries = h.global.snapshot()
+	}
+
+	var result []ServiceEvent
+	for _, entry := range entries {
+		if filter.Match(entry.event) {
+			result = append(result, entry.event)
+		}
+	}
+	return result
+}
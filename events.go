@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +20,19 @@ const (
 	EventError       EventType = "Error"
 	EventHealthCheck EventType = "HealthCheck"
 	EventStateChange EventType = "StateChange"
+	EventUpdate      EventType = "Update"
+	EventAdd         EventType = "Add"
+	EventRemove      EventType = "Remove"
+)
+
+// 事件元数据的键
+const (
+	MetadataDuration = "duration" // time.Duration，步骤耗时或在前一状态停留的时间
+	MetadataFrom     = "from"     // ServiceState，状态变更前的状态
+	MetadataTo       = "to"       // ServiceState，状态变更后的状态
+	MetadataPhase    = "phase"    // EventType，Error 事件对应的生命周期步骤
+	MetadataReady    = "ready"    // bool，健康检查时的就绪探针结果
+	MetadataLive     = "live"     // bool，健康检查时的存活探针结果
 )
 
 // ServiceEvent 服务事件
@@ -33,65 +50,481 @@ type EventListener interface {
 	OnServiceEvent(event ServiceEvent)
 }
 
+// DeliveryMode 事件投递模式
+type DeliveryMode int
+
+const (
+	// DeliveryOrdered 每个监听器拥有独立的有界队列和投递协程，按发布顺序依次投递
+	DeliveryOrdered DeliveryMode = iota
+	// DeliverySync 在 PublishEvent 中同步调用监听器
+	DeliverySync
+)
+
+// String 返回投递模式的字符串表示
+func (m DeliveryMode) String() string {
+	switch m {
+	case DeliveryOrdered:
+		return "Ordered"
+	case DeliverySync:
+		return "Sync"
+	default:
+		return fmt.Sprintf("DeliveryMode(%d)", int(m))
+	}
+}
+
+// OverflowPolicy 监听器队列已满时的处理策略
+type OverflowPolicy int
+
+const (
+	// OverflowBlock 阻塞发布方直到队列有空位
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop 丢弃新事件并计入 Dropped
+	OverflowDrop
+)
+
+// String 返回溢出策略的字符串表示
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowDrop:
+		return "Drop"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// EventManagerOptions 事件管理器配置
+type EventManagerOptions struct {
+	Mode       DeliveryMode   // 投递模式
+	BufferSize int            // 每个监听器的队列长度，仅用于 DeliveryOrdered
+	Overflow   OverflowPolicy // 监听器队列已满时的处理策略，仅用于 DeliveryOrdered；通道订阅参见 WithOverflow
+
+	HistorySize        int // 全局保留的最近事件数，0 使用默认值，负数表示不保留
+	ServiceHistorySize int // 每个服务保留的最近事件数，0 使用默认值，负数表示不保留
+}
+
+// DefaultEventManagerOptions 默认事件管理器配置
+var DefaultEventManagerOptions = EventManagerOptions{
+	Mode:       DeliveryOrdered,
+	BufferSize: 256,
+	Overflow:   OverflowBlock,
+
+	HistorySize:        1000,
+	ServiceHistorySize: 100,
+}
+
+// normalize 填充未设置的配置项
+func (o EventManagerOptions) normalize() EventManagerOptions {
+	if o.BufferSize <= 0 {
+		o.BufferSize = DefaultEventManagerOptions.BufferSize
+	}
+	if o.HistorySize == 0 {
+		o.HistorySize = DefaultEventManagerOptions.HistorySize
+	}
+	if o.ServiceHistorySize == 0 {
+		o.ServiceHistorySize = DefaultEventManagerOptions.ServiceHistorySize
+	}
+	return o
+}
+
+// EventAll 匹配所有事件类型的通配符，可用于 AddListener
+const EventAll EventType = "*"
+
+// ErrorMatch 按事件是否携带错误过滤
+type ErrorMatch int
+
+const (
+	ErrorAny     ErrorMatch = iota // 不按错误过滤
+	ErrorPresent                   // 只匹配携带错误的事件
+	ErrorAbsent                    // 只匹配不携带错误的事件
+)
+
+// EventFilter 事件过滤条件，各字段为空时不参与过滤，多个字段之间为“与”关系
+type EventFilter struct {
+	Services []string       // 服务名
+	Types    []EventType    // 事件类型
+	From     []ServiceState // 状态变更前的状态，设置后只匹配 StateChange 事件
+	To       []ServiceState // 状态变更后的状态，设置后只匹配 StateChange 事件
+	Errors   ErrorMatch     // 是否携带错误
+}
+
+// Match 判断事件是否满足过滤条件
+func (f EventFilter) Match(event ServiceEvent) bool {
+	if len(f.Services) > 0 && !contains(f.Services, event.ServiceName) {
+		return false
+	}
+	if len(f.Types) > 0 && !contains(f.Types, event.EventType) {
+		return false
+	}
+	if len(f.From) > 0 || len(f.To) > 0 {
+		if event.EventType != EventStateChange {
+			return false
+		}
+		if len(f.From) > 0 && !matchState(f.From, event.Metadata[MetadataFrom]) {
+			return false
+		}
+		if len(f.To) > 0 && !matchState(f.To, event.Metadata[MetadataTo]) {
+			return false
+		}
+	}
+	switch f.Errors {
+	case ErrorPresent:
+		return event.Error != nil
+	case ErrorAbsent:
+		return event.Error == nil
+	}
+	return true
+}
+
+// contains 判断切片中是否包含指定值
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// matchState 判断元数据中的状态是否在列表中
+func matchState(states []ServiceState, value interface{}) bool {
+	state, ok := value.(ServiceState)
+	return ok && contains(states, state)
+}
+
+// ListenerID 监听器注册标识，可比较，用于移除监听器
+type ListenerID uint64
+
 // EventManager 事件管理器
 type EventManager struct {
-	mu        sync.RWMutex
-	listeners map[EventType][]EventListener
+	mu            sync.RWMutex
+	options       EventManagerOptions
+	subscriptions []*subscription
+	nextID        ListenerID
+	history       *eventHistory
+	logger        Logger
+	closed        bool
+	closeOnce     sync.Once
+	wg            sync.WaitGroup
+	dropped       atomic.Uint64
+}
+
+// subscription 一次监听器或通道订阅
+type subscription struct {
+	id       ListenerID
+	key      EventType // AddListener 注册时的事件类型，用于 RemoveListener
+	filter   EventFilter
+	listener EventListener // 为 nil 时表示通道订阅
+	events   chan ServiceEvent
+	stop     chan struct{}
+	stopOnce sync.Once
+	sendMu   sync.RWMutex // 保护通道订阅 events 的关闭
+	replay   int          // 通道订阅注册时回放的历史事件数
+	overflow OverflowPolicy
 }
 
 // NewEventManager 创建新的事件管理器
-func NewEventManager() *EventManager {
+func NewEventManager(opts ...EventManagerOptions) *EventManager {
+	options := DefaultEventManagerOptions
+	if len(opts) > 0 {
+		options = opts[0].normalize()
+	}
+
 	return &EventManager{
-		listeners: make(map[EventType][]EventListener),
+		options: options,
+		history: newEventHistory(options.HistorySize, options.ServiceHistorySize),
+		logger:  defaultLogger(),
+	}
+}
+
+// AddListener 添加事件监听器，返回用于移除的标识
+// eventType 为 EventAll 时接收所有类型的事件；每次注册的监听器都按发布顺序接收事件
+func (em *EventManager) AddListener(eventType EventType, listener EventListener) ListenerID {
+	var filter EventFilter
+	if eventType != EventAll {
+		filter.Types = []EventType{eventType}
+	}
+	return em.register(&subscription{key: eventType, filter: filter, listener: listener})
+}
+
+// AddFilteredListener 添加只接收满足过滤条件事件的监听器，返回用于移除的标识
+func (em *EventManager) AddFilteredListener(filter EventFilter, listener EventListener) ListenerID {
+	return em.register(&subscription{filter: filter, listener: listener})
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*subscribeOptions)
+
+// subscribeOptions 订阅配置
+type subscribeOptions struct {
+	replay   int
+	overflow OverflowPolicy
+}
+
+// WithReplay 订阅时先从历史记录中回放最近 n 个满足过滤条件的事件，再接收新事件
+// 回放的事件数不超过 BufferSize
+func WithReplay(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.replay = n
+	}
+}
+
+// WithOverflow 设置通道缓冲区已满时的处理策略，默认为 OverflowDrop
+// 使用 OverflowBlock 时，停止读取通道会阻塞服务组的启动、停止和健康检查
+func WithOverflow(policy OverflowPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.overflow = policy
 	}
 }
 
-// AddListener 添加事件监听器
-func (em *EventManager) AddListener(eventType EventType, listener EventListener) {
+// Subscribe 订阅满足过滤条件的事件
+// 返回的通道在调用 unsubscribe、ctx 结束或事件管理器关闭后关闭；
+// 通道缓冲区满时默认丢弃新事件并计入 Dropped，不受 EventManagerOptions.Overflow 影响，可通过 WithOverflow 修改
+func (em *EventManager) Subscribe(ctx context.Context, filter EventFilter, opts ...SubscribeOption) (<-chan ServiceEvent, func()) {
+	o := subscribeOptions{overflow: OverflowDrop}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sub := &subscription{
+		filter:   filter,
+		events:   make(chan ServiceEvent, em.options.BufferSize),
+		replay:   min(o.replay, em.options.BufferSize),
+		overflow: o.overflow,
+	}
+	id := em.register(sub)
+	unsubscribe := func() {
+		em.RemoveListenerByID(id)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+		case <-sub.stop:
+		}
+	}()
+	return sub.events, unsubscribe
+}
+
+// register 注册订阅，事件管理器已关闭时订阅立即关闭
+func (em *EventManager) register(sub *subscription) ListenerID {
+	sub.stop = make(chan struct{})
+	if sub.listener != nil {
+		sub.overflow = em.options.Overflow
+		if em.options.Mode == DeliveryOrdered {
+			sub.events = make(chan ServiceEvent, em.options.BufferSize)
+		}
+	}
+
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
-	if _, exists := em.listeners[eventType]; !exists {
-		em.listeners[eventType] = make([]EventListener, 0)
+	em.nextID++
+	sub.id = em.nextID
+	if em.closed {
+		sub.close()
+		return sub.id
+	}
+
+	if sub.listener != nil && em.options.Mode == DeliveryOrdered {
+		em.wg.Add(1)
+		go em.drain(sub)
+	}
+	// 在持有锁时回放，保证回放的事件与之后发布的事件之间既不重复也不遗漏
+	if sub.replay > 0 {
+		replay := em.history.query(sub.filter)
+		if len(replay) > sub.replay {
+			replay = replay[len(replay)-sub.replay:]
+		}
+		for _, event := range replay {
+			sub.events <- event
+		}
 	}
-	em.listeners[eventType] = append(em.listeners[eventType], listener)
+	em.subscriptions = append(em.subscriptions, sub)
+	return sub.id
 }
 
-// RemoveListener 移除事件监听器
+// RemoveListener 移除通过 AddListener 注册的事件监听器，已进入队列的事件仍会被投递
+// 监听器的动态类型不可比较时（例如 DefaultEventListener 值）无法匹配，请改用 RemoveListenerByID
 func (em *EventManager) RemoveListener(eventType EventType, listener EventListener) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
-	if listeners, exists := em.listeners[eventType]; exists {
-		for i, l := range listeners {
-			if l == listener {
-				em.listeners[eventType] = append(listeners[:i], listeners[i+1:]...)
-				break
-			}
+	for i, sub := range em.subscriptions {
+		if sub.key == eventType && sameListener(sub.listener, listener) {
+			em.subscriptions = append(em.subscriptions[:i:i], em.subscriptions[i+1:]...)
+			sub.close()
+			return
+		}
+	}
+}
+
+// RemoveListenerByID 移除监听器或通道订阅，已进入队列的事件仍会被投递
+func (em *EventManager) RemoveListenerByID(id ListenerID) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	for i, sub := range em.subscriptions {
+		if sub.id == id {
+			em.subscriptions = append(em.subscriptions[:i:i], em.subscriptions[i+1:]...)
+			sub.close()
+			return
 		}
 	}
 }
 
+// sameListener 比较两个监听器，动态类型不可比较时视为不同，避免比较时 panic
+func sameListener(a, b EventListener) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
+
 // PublishEvent 发布事件
+// 在 DeliveryOrdered 模式下使用 OverflowBlock 时，监听器不应在回调中向自身发布事件，否则可能死锁
 func (em *EventManager) PublishEvent(event ServiceEvent) {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
+	em.mu.Lock()
+	if em.closed {
+		em.mu.Unlock()
+		return
+	}
+	em.history.record(event)
+	targets := make([]*subscription, 0, len(em.subscriptions))
+	for _, sub := range em.subscriptions {
+		if sub.filter.Match(event) {
+			targets = append(targets, sub)
+		}
+	}
+	em.mu.Unlock()
 
-	// 通知特定类型的监听器
-	if listeners, exists := em.listeners[event.EventType]; exists {
-		for _, listener := range listeners {
-			go listener.OnServiceEvent(event)
+	for _, sub := range targets {
+		if sub.listener != nil && em.options.Mode == DeliverySync {
+			em.deliver(sub.listener, event)
+			continue
 		}
+		em.enqueue(sub, event)
 	}
+}
 
-	// 通知通用监听器（如果有的话）
-	if listeners, exists := em.listeners["*"]; exists {
-		for _, listener := range listeners {
-			go listener.OnServiceEvent(event)
+// enqueue 将事件放入订阅队列
+func (em *EventManager) enqueue(sub *subscription, event ServiceEvent) {
+	sub.sendMu.RLock()
+	defer sub.sendMu.RUnlock()
+
+	select {
+	case <-sub.stop:
+		return
+	default:
+	}
+
+	if sub.overflow == OverflowDrop {
+		select {
+		case sub.events <- event:
+		default:
+			em.dropped.Add(1)
 		}
+		return
+	}
+
+	select {
+	case sub.events <- event:
+	case <-sub.stop:
 	}
 }
 
+// drain 按顺序投递队列中的事件，订阅关闭后投递剩余事件再退出
+func (em *EventManager) drain(sub *subscription) {
+	defer em.wg.Done()
+
+	for {
+		select {
+		case event := <-sub.events:
+			em.deliver(sub.listener, event)
+		case <-sub.stop:
+			for {
+				select {
+				case event := <-sub.events:
+					em.deliver(sub.listener, event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// close 关闭订阅；通道订阅会在没有发送方后关闭通道，已缓冲的事件仍可读取
+func (sub *subscription) close() {
+	sub.stopOnce.Do(func() {
+		close(sub.stop)
+		if sub.listener == nil {
+			sub.sendMu.Lock()
+			close(sub.events)
+			sub.sendMu.Unlock()
+		}
+	})
+}
+
+// deliver 调用监听器并恢复其中的 panic
+func (em *EventManager) deliver(listener EventListener, event ServiceEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			em.logger.Error("Event listener panicked",
+				"service", event.ServiceName,
+				"event", event.EventType,
+				"panic", r)
+		}
+	}()
+	listener.OnServiceEvent(event)
+}
+
+// History 按从旧到新的顺序返回历史记录中满足过滤条件的事件
+// 指定 filter.Services 时从按服务保留的记录中查询，否则从全局记录中查询
+// 健康检查事件只在结果变化时记入历史
+func (em *EventManager) History(filter EventFilter) []ServiceEvent {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return em.history.query(filter)
+}
+
+// Dropped 返回因队列已满而被丢弃的事件数
+func (em *EventManager) Dropped() uint64 {
+	return em.dropped.Load()
+}
+
+// Close 停止接收新事件，关闭所有订阅通道，并等待所有已入队的事件投递给监听器
+// 重复调用是安全的
+func (em *EventManager) Close() {
+	em.closeOnce.Do(func() {
+		em.mu.Lock()
+		em.closed = true
+		subs := em.subscriptions
+		em.subscriptions = nil
+		em.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.close()
+		}
+	})
+	em.wg.Wait()
+}
+
+// EventListenerFunc 函数形式的事件监听器
+// 函数不可比较，无法通过 RemoveListener 移除，请使用 RemoveListenerByID
+type EventListenerFunc func(event ServiceEvent)
+
+// OnServiceEvent 实现 EventListener 接口
+func (f EventListenerFunc) OnServiceEvent(event ServiceEvent) {
+	f(event)
+}
+
 // DefaultEventListener 默认事件监听器实现
 type DefaultEventListener struct {
 	OnEventFunc func(event ServiceEvent)
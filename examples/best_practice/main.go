@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/darkit/service"
@@ -50,20 +51,6 @@ func main() {
 		log.Fatalf("Failed to add API service: %v", err)
 	}
 
-	// 启动服务组
-	if err := sg.Start(); err != nil {
-		log.Fatalf("Failed to start services: %v", err)
-	}
-
-	// 等待服务启动完成
-	if err := sg.WaitForStart(ctx); err != nil {
-		log.Fatalf("Error waiting for services to start: %v", err)
-	}
-
-	// 获取服务状态
-	state := sg.GetGroupState()
-	log.Printf("Running services: %d/%d", state.RunningServices, state.TotalServices)
-
 	// 监控服务指标
 	go func() {
 		ticker := time.NewTicker(time.Minute)
@@ -84,14 +71,20 @@ func main() {
 		}
 	}()
 
-	// 等待中断信号
-	<-ctx.Done()
-
-	// 优雅停止服务
-	stopCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	defer cancel()
-
-	if err := sg.GracefulStop(stopCtx); err != nil {
-		log.Printf("Error stopping services: %v", err)
+	// 启动服务组并阻塞，直到收到 SIGINT/SIGTERM 后优雅停止
+	// 收到 SIGHUP 时重新加载配置并更新服务
+	code, err := sg.Run(ctx, service.WithReload(func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"database": &DatabaseConfig{
+				DSN:            "postgres://localhost:5432/mydb",
+				MaxConnections: 20,
+				ConnectTimeout: time.Second * 5,
+				RetryAttempts:  3,
+			},
+		}, nil
+	}))
+	if err != nil {
+		log.Printf("Service group exited with error: %v", err)
 	}
+	os.Exit(code)
 }
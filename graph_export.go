@@ -0,0 +1,391 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GraphOption 依赖图导出选项
+type GraphOption func(*graphOptions)
+
+// graphOptions 依赖图导出配置
+type graphOptions struct {
+	group *ServiceGroup
+}
+
+// WithServiceGroup 使用服务组中服务的当前状态和最近一次探针结果标注节点
+func WithServiceGroup(sg *ServiceGroup) GraphOption {
+	return func(o *graphOptions) {
+		o.group = sg
+	}
+}
+
+// 节点的健康状态，由服务状态和最近一次探针结果决定
+const (
+	HealthHealthy   = "healthy"   // Running，且探针通过或尚未探测
+	HealthDegraded  = "degraded"  // Running 但未就绪，或处于 Starting/Stopping
+	HealthUnhealthy = "unhealthy" // Error，或存活探针失败
+	HealthInactive  = "inactive"  // 未运行
+	HealthMissing   = "missing"   // 被依赖但不在依赖图中
+)
+
+// healthColors 各健康状态的节点填充色
+var healthColors = map[string]string{
+	HealthHealthy:   "#c8e6c9",
+	HealthDegraded:  "#fff3c4",
+	HealthUnhealthy: "#ffcdd2",
+	HealthInactive:  "#eeeeee",
+	HealthMissing:   "#ffffff",
+}
+
+// graphView 导出时使用的依赖图快照
+type graphView struct {
+	nodes   []graphViewNode
+	missing []string
+}
+
+// graphViewNode 依赖图快照中的节点
+type graphViewNode struct {
+	name     string
+	priority ServicePriority
+	state    ServiceState
+	hasState bool
+	health   string
+	missing  bool
+	required bool // 缺失的节点是否被必需依赖引用
+	cycle    bool
+	edges    []graphViewEdge
+}
+
+// graphViewEdge 依赖图快照中的边，由依赖方指向依赖
+type graphViewEdge struct {
+	to      string
+	kind    DependencyKind
+	missing bool
+	cycle   bool
+}
+
+// view 生成依赖图快照，标记循环依赖和缺失的依赖
+func (dg *DependencyGraph) view(opts []GraphOption) graphView {
+	var o graphOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dg.mu.RLock()
+	cycles := dg.cycleEdges()
+	var v graphView
+	missing := make(map[string]bool)
+	for _, node := range dg.nodes {
+		n := graphViewNode{
+			name:     node.Name,
+			priority: node.Priority,
+		}
+		kinds := []struct {
+			kind DependencyKind
+			deps []string
+		}{
+			{DependencyRequired, node.Deps},
+			{DependencyOptional, node.Optional},
+			{DependencyWeak, node.Weak},
+			{DependencyAfter, node.After},
+		}
+		for _, k := range kinds {
+			for _, dep := range k.deps {
+				e := graphViewEdge{to: dep, kind: k.kind}
+				if _, exists := dg.nodes[dep]; !exists {
+					e.missing = true
+					missing[dep] = missing[dep] || k.kind == DependencyRequired
+				}
+				if cycles[[2]string{node.Name, dep}] {
+					e.cycle = true
+					n.cycle = true
+				}
+				n.edges = append(n.edges, e)
+			}
+		}
+		v.nodes = append(v.nodes, n)
+	}
+	dg.mu.RUnlock()
+
+	for name, required := range missing {
+		v.missing = append(v.missing, name)
+		v.nodes = append(v.nodes, graphViewNode{
+			name:     name,
+			health:   HealthMissing,
+			missing:  true,
+			required: required,
+		})
+	}
+	sort.Strings(v.missing)
+	sort.Slice(v.nodes, func(i, j int) bool {
+		return v.nodes[i].name < v.nodes[j].name
+	})
+
+	if o.group != nil {
+		for i := range v.nodes {
+			n := &v.nodes[i]
+			if n.missing {
+				continue
+			}
+			svc, err := o.group.GetService(n.name)
+			if err != nil {
+				continue
+			}
+			n.state = svc.State()
+			n.hasState = true
+			status, probed := o.group.probes.get(n.name)
+			n.health = serviceHealth(n.state, status, probed)
+		}
+	}
+	return v
+}
+
+// serviceHealth 根据服务状态和最近一次探针结果计算健康状态
+func serviceHealth(state ServiceState, status ProbeStatus, probed bool) string {
+	switch state {
+	case StateError:
+		return HealthUnhealthy
+	case StateRunning:
+		switch {
+		case probed && !status.Live:
+			return HealthUnhealthy
+		case probed && !status.Ready:
+			return HealthDegraded
+		default:
+			return HealthHealthy
+		}
+	case StateStarting, StateStopping:
+		return HealthDegraded
+	default:
+		return HealthInactive
+	}
+}
+
+// cycleEdges 返回位于循环中的边，调用方需持有读锁
+// 两端位于同一个包含循环的强连通分量中的边即循环边
+func (dg *DependencyGraph) cycleEdges() map[[2]string]bool {
+	component, _ := dg.components()
+	edges := make(map[[2]string]bool)
+	for name, c := range component {
+		for _, dep := range dg.nodes[name].orderDeps() {
+			if d, ok := component[dep]; ok && d == c {
+				edges[[2]string{name, dep}] = true
+			}
+		}
+	}
+	return edges
+}
+
+// WriteDOT 以 Graphviz DOT 格式导出依赖图，边由依赖方指向依赖
+// 必需依赖为实线，可选依赖为虚线，弱依赖为点线，顺序提示为灰色点线；循环依赖以红色粗线标出
+// 缺失的依赖以虚线框标出，缺失的必需依赖及指向它的边为红色
+func (dg *DependencyGraph) WriteDOT(w io.Writer, opts ...GraphOption) error {
+	v := dg.view(opts)
+
+	var b strings.Builder
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, n := range v.nodes {
+		label := n.name
+		var attrs []string
+		if n.missing {
+			label += "\\n(missing)"
+			attrs = append(attrs, `style="rounded,dashed"`)
+			if n.required {
+				attrs = append(attrs, `color="#c62828"`, `fontcolor="#c62828"`)
+			} else {
+				attrs = append(attrs, `color="#9e9e9e"`, `fontcolor="#9e9e9e"`)
+			}
+		} else {
+			label += fmt.Sprintf("\\npriority %d", n.priority)
+			if n.hasState {
+				label += "\\n" + n.state.String()
+			}
+			if n.cycle {
+				attrs = append(attrs, `color="#c62828"`, "penwidth=2")
+			}
+		}
+		if color, ok := healthColors[n.health]; ok {
+			attrs = append(attrs, fmt.Sprintf("fillcolor=%q", color))
+		}
+		attrs = append([]string{fmt.Sprintf("label=%s", dotQuote(label))}, attrs...)
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.name), strings.Join(attrs, ", "))
+	}
+
+	for _, n := range v.nodes {
+		for _, e := range n.edges {
+			var attrs []string
+			color := ""
+			switch e.kind {
+			case DependencyOptional:
+				attrs = append(attrs, "style=dashed", `label="optional"`)
+			case DependencyWeak:
+				attrs = append(attrs, "style=dotted", `label="weak"`)
+			case DependencyAfter:
+				attrs = append(attrs, "style=dotted", `label="after"`)
+				color = "#9e9e9e"
+			}
+			if e.cycle {
+				color = "#c62828"
+				attrs = append(attrs, "penwidth=2")
+			} else if e.missing && e.kind == DependencyRequired {
+				color = "#c62828"
+			}
+			if color != "" {
+				attrs = append(attrs, fmt.Sprintf("color=%q", color))
+			}
+			fmt.Fprintf(&b, "  %s -> %s", dotQuote(n.name), dotQuote(e.to))
+			if len(attrs) > 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+			}
+			b.WriteString(";\n")
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote 返回 DOT 的带引号字符串，保留标签中的 \n 换行
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// WriteMermaid 以 Mermaid flowchart 格式导出依赖图，边由依赖方指向依赖
+// 循环依赖和指向缺失的必需依赖的边以红色标出，缺失的必需依赖使用 missing 样式，其他缺失的依赖使用 missingOptional 样式
+func (dg *DependencyGraph) WriteMermaid(w io.Writer, opts ...GraphOption) error {
+	v := dg.view(opts)
+
+	// 服务名可能包含 Mermaid 不允许的字符，节点使用生成的 ID
+	ids := make(map[string]string, len(v.nodes))
+	for i, n := range v.nodes {
+		ids[n.name] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range v.nodes {
+		label := n.name
+		if n.missing {
+			label += "<br/>(missing)"
+		} else {
+			label += fmt.Sprintf("<br/>priority %d", n.priority)
+			if n.hasState {
+				label += "<br/>" + n.state.String()
+			}
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]", ids[n.name], strings.ReplaceAll(label, `"`, "#quot;"))
+		switch {
+		case n.missing && !n.required:
+			b.WriteString(":::missingOptional")
+		case n.health != "":
+			fmt.Fprintf(&b, ":::%s", n.health)
+		}
+		b.WriteByte('\n')
+	}
+
+	var redLinks []string
+	link := 0
+	for _, n := range v.nodes {
+		for _, e := range n.edges {
+			arrow := "-->"
+			switch e.kind {
+			case DependencyOptional:
+				arrow = "-.->|optional|"
+			case DependencyWeak:
+				arrow = "-.->|weak|"
+			case DependencyAfter:
+				arrow = "-.->|after|"
+			}
+			fmt.Fprintf(&b, "  %s %s %s\n", ids[n.name], arrow, ids[e.to])
+			if e.cycle || (e.missing && e.kind == DependencyRequired) {
+				redLinks = append(redLinks, fmt.Sprint(link))
+			}
+			link++
+		}
+	}
+
+	for _, health := range []string{HealthHealthy, HealthDegraded, HealthUnhealthy, HealthInactive} {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", health, healthColors[health])
+	}
+	fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:#c62828,stroke-dasharray:5 5\n", HealthMissing, healthColors[HealthMissing])
+	fmt.Fprintf(&b, "  classDef missingOptional fill:%s,stroke:#9e9e9e,stroke-dasharray:5 5\n", healthColors[HealthMissing])
+	if len(redLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#c62828,stroke-width:2px\n", strings.Join(redLinks, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graphJSON 依赖图的 JSON 邻接表
+type graphJSON struct {
+	Nodes   []graphNodeJSON `json:"nodes"`
+	Missing []string        `json:"missing,omitempty"`
+	Cyclic  bool            `json:"cyclic"`
+}
+
+// graphNodeJSON 邻接表中的节点
+type graphNodeJSON struct {
+	Name         string          `json:"name"`
+	Priority     ServicePriority `json:"priority"`
+	State        *ServiceState   `json:"state,omitempty"`
+	Health       string          `json:"health,omitempty"`
+	Missing      bool            `json:"missing,omitempty"`
+	Cycle        bool            `json:"cycle,omitempty"`
+	Dependencies []graphEdgeJSON `json:"dependencies"`
+}
+
+// graphEdgeJSON 邻接表中的边
+type graphEdgeJSON struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Missing bool   `json:"missing,omitempty"`
+	Cycle   bool   `json:"cycle,omitempty"`
+}
+
+// WriteJSON 以 JSON 邻接表格式导出依赖图，每个节点列出它的依赖及依赖类型
+func (dg *DependencyGraph) WriteJSON(w io.Writer, opts ...GraphOption) error {
+	v := dg.view(opts)
+
+	out := graphJSON{
+		Nodes:   make([]graphNodeJSON, 0, len(v.nodes)),
+		Missing: v.missing,
+	}
+	for _, n := range v.nodes {
+		node := graphNodeJSON{
+			Name:         n.name,
+			Priority:     n.priority,
+			Health:       n.health,
+			Missing:      n.missing,
+			Cycle:        n.cycle,
+			Dependencies: make([]graphEdgeJSON, 0, len(n.edges)),
+		}
+		if n.hasState {
+			state := n.state
+			node.State = &state
+		}
+		for _, e := range n.edges {
+			node.Dependencies = append(node.Dependencies, graphEdgeJSON{
+				Name:    e.to,
+				Kind:    e.kind.String(),
+				Missing: e.missing,
+				Cycle:   e.cycle,
+			})
+		}
+		out.Cyclic = out.Cyclic || n.cycle
+		out.Nodes = append(out.Nodes, node)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultLatencyBuckets 默认的耗时直方图桶上界（秒）
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// histogram 固定桶的直方图
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64 // 每个桶（非累计）的计数，超过最大上界的样本只计入 count
+	count   uint64
+	sum     float64
+	min     float64
+	max     float64
+}
+
+// histogramSnapshot 直方图快照，Counts 为累计计数
+type histogramSnapshot struct {
+	Buckets []float64
+	Counts  []uint64
+	Count   uint64
+	Sum     float64
+	Min     float64
+	Max     float64
+}
+
+// newHistogram 创建直方图，buckets 为递增的桶上界
+func newHistogram(buckets []float64) *histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &histogram{
+		buckets: b,
+		counts:  make([]uint64, len(b)),
+	}
+}
+
+// observe 记录一个样本
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	if h.count == 0 || v < h.min {
+		h.min = v
+	}
+	if h.count == 0 || v > h.max {
+		h.max = v
+	}
+	h.count++
+	h.sum += v
+}
+
+// snapshot 获取直方图快照
+func (h *histogram) snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := histogramSnapshot{
+		Buckets: append([]float64(nil), h.buckets...),
+		Counts:  make([]uint64, len(h.counts)),
+		Count:   h.count,
+		Sum:     h.sum,
+		Min:     h.min,
+		Max:     h.max,
+	}
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		s.Counts[i] = cumulative
+	}
+	return s
+}
+
+// quantile 根据桶计数估算分位数，q 取值 [0, 1]
+// 在样本所在的桶内线性插值，并用观测到的最小值和最大值限定结果
+func (s histogramSnapshot) quantile(q float64) float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	rank := q * float64(s.Count)
+
+	lower, prev := s.Min, uint64(0)
+	for i, upper := range s.Buckets {
+		cumulative := s.Counts[i]
+		if float64(cumulative) >= rank && cumulative > prev {
+			upper = math.Min(upper, s.Max)
+			lower = math.Max(lower, s.Min)
+			if upper <= lower {
+				return upper
+			}
+			return lower + (upper-lower)*(rank-float64(prev))/float64(cumulative-prev)
+		}
+		lower, prev = upper, cumulative
+	}
+
+	// 落在最大上界之外的样本，在最后一个上界与最大值之间插值
+	lower = math.Max(lower, s.Min)
+	if s.Max <= lower || s.Count == prev {
+		return s.Max
+	}
+	return lower + (s.Max-lower)*(rank-float64(prev))/float64(s.Count-prev)
+}
@@ -0,0 +1,496 @@
+// Package httpadmin 为 ServiceGroup 提供 HTTP 管理与健康检查接口
+//
+// 路由：
+//
+//	GET  /healthz                          存活与就绪探针均通过时返回 200
+//	GET  /readyz                           就绪探针，对应 Kubernetes readinessProbe
+//	GET  /livez                            存活探针，对应 Kubernetes livenessProbe
+//	GET  /metrics                          Prometheus 文本格式指标
+//	GET  /api/state                        服务组状态（GetGroupState）
+//	GET  /api/metrics                      所有服务的指标
+//	GET  /api/graph                        依赖图，format 查询参数可选 json（默认）、dot、mermaid
+//	GET  /api/events                       最近的事件，支持 service、type、errors、limit 查询参数
+//	GET  /api/boot                         最近一次启动的报告，format 查询参数可选 json（默认）、text、chrome
+//	POST /api/services/{name}/start        启动服务
+//	POST /api/services/{name}/stop         停止服务
+//	POST /api/services/{name}/restart      重启服务
+package httpadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkit/service"
+)
+
+// Handler 服务组管理 HTTP 处理器
+type Handler struct {
+	group         *service.ServiceGroup
+	mux           *http.ServeMux
+	actionTimeout time.Duration
+}
+
+// Option 处理器配置选项
+type Option func(*Handler)
+
+// WithActionTimeout 设置启动、停止、重启操作的超时时间
+func WithActionTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.actionTimeout = timeout
+	}
+}
+
+// New 创建服务组管理处理器
+func New(sg *service.ServiceGroup, opts ...Option) *Handler {
+	h := &Handler{
+		group:         sg,
+		mux:           http.NewServeMux(),
+		actionTimeout: time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("/healthz", h.handleHealthz)
+	h.mux.HandleFunc("/readyz", h.handleReadyz)
+	h.mux.HandleFunc("/livez", h.handleLivez)
+	h.mux.Handle("/metrics", sg.PrometheusHandler())
+	h.mux.HandleFunc("/api/state", h.handleState)
+	h.mux.HandleFunc("/api/metrics", h.handleMetrics)
+	h.mux.HandleFunc("/api/graph", h.handleGraph)
+	h.mux.HandleFunc("/api/events", h.handleEvents)
+	h.mux.HandleFunc("/api/boot", h.handleBoot)
+	h.mux.HandleFunc("/api/services/", h.handleServiceAction)
+	return h
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// probeResponse 探针响应
+type probeResponse struct {
+	Status   string                  `json:"status"`
+	Services map[string]serviceProbe `json:"services"`
+}
+
+// serviceProbe 单个服务的探针结果
+type serviceProbe struct {
+	Ready          bool   `json:"ready"`
+	Live           bool   `json:"live"`
+	ReadinessError string `json:"readinessError,omitempty"`
+	LivenessError  string `json:"livenessError,omitempty"`
+}
+
+func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	h.handleProbe(w, r, func(s service.GroupProbeStatus) bool {
+		return s.Ready && s.Live
+	})
+}
+
+func (h *Handler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	h.handleProbe(w, r, func(s service.GroupProbeStatus) bool {
+		return s.Ready
+	})
+}
+
+func (h *Handler) handleLivez(w http.ResponseWriter, r *http.Request) {
+	h.handleProbe(w, r, func(s service.GroupProbeStatus) bool {
+		return s.Live
+	})
+}
+
+// handleProbe 对所有服务执行一次探针并返回各服务的结果，passed 不满足时返回 503
+func (h *Handler) handleProbe(w http.ResponseWriter, r *http.Request, passed func(service.GroupProbeStatus) bool) {
+	if !allowMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	status := h.group.Probe(r.Context())
+
+	resp := probeResponse{
+		Status:   "ok",
+		Services: make(map[string]serviceProbe, len(status.Services)),
+	}
+	for name, s := range status.Services {
+		resp.Services[name] = serviceProbe{
+			Ready:          s.Ready,
+			Live:           s.Live,
+			ReadinessError: errorString(s.ReadinessError),
+			LivenessError:  errorString(s.LivenessError),
+		}
+	}
+
+	code := http.StatusOK
+	if !passed(status) {
+		resp.Status = "fail"
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, resp)
+}
+
+func (h *Handler) handleState(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.group.GetGroupState())
+}
+
+// metricsResponse 单个服务的指标
+type metricsResponse struct {
+	State             service.ServiceState `json:"state"`
+	StartTime         time.Time            `json:"startTime"`
+	StartCount        int64                `json:"startCount"`
+	StopCount         int64                `json:"stopCount"`
+	RestartCount      int64                `json:"restartCount"`
+	ErrorCount        int64                `json:"errorCount"`
+	LastError         string               `json:"lastError,omitempty"`
+	LastErrorTime     time.Time            `json:"lastErrorTime"`
+	HealthCheckCount  int64                `json:"healthCheckCount"`
+	HealthCheckErrors int64                `json:"healthCheckErrors"`
+	LastHealthCheck   time.Time            `json:"lastHealthCheck"`
+	TotalUptime       float64              `json:"totalUptimeSeconds"`
+	LastStateChange   time.Time            `json:"lastStateChange"`
+
+	Latency      map[service.EventType]latencyResponse `json:"latency,omitempty"`
+	Availability map[string]float64                    `json:"availability,omitempty"`
+}
+
+// latencyResponse 生命周期步骤耗时统计，单位为秒
+type latencyResponse struct {
+	Count       uint64             `json:"count"`
+	Mean        float64            `json:"mean"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+func (h *Handler) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	resp := make(map[string]metricsResponse)
+	for _, name := range h.group.ListServices() {
+		m, err := h.group.GetServiceMetrics(name)
+		if err != nil {
+			continue
+		}
+		latency := make(map[service.EventType]latencyResponse, len(m.Latency))
+		for phase, l := range m.Latency {
+			percentiles := make(map[string]float64, len(l.Percentiles))
+			for q, d := range l.Percentiles {
+				percentiles["p"+strconv.FormatFloat(q*100, 'g', -1, 64)] = d.Seconds()
+			}
+			latency[phase] = latencyResponse{
+				Count:       l.Count,
+				Mean:        l.Mean.Seconds(),
+				Min:         l.Min.Seconds(),
+				Max:         l.Max.Seconds(),
+				Percentiles: percentiles,
+			}
+		}
+		availability := make(map[string]float64, len(m.Availability))
+		for window, ratio := range m.Availability {
+			availability[window.String()] = ratio
+		}
+
+		resp[name] = metricsResponse{
+			State:             m.State,
+			StartTime:         m.StartTime,
+			StartCount:        m.StartCount,
+			StopCount:         m.StopCount,
+			RestartCount:      m.RestartCount,
+			ErrorCount:        m.ErrorCount,
+			LastError:         errorString(m.LastError),
+			LastErrorTime:     m.LastErrorTime,
+			HealthCheckCount:  m.HealthCheckCount,
+			HealthCheckErrors: m.HealthCheckErrors,
+			LastHealthCheck:   m.LastHealthCheck,
+			TotalUptime:       m.TotalUptime.Seconds(),
+			LastStateChange:   m.LastStateChange,
+			Latency:           latency,
+			Availability:      availability,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// graphNode 依赖图节点
+type graphNode struct {
+	Name     string                  `json:"name"`
+	Priority service.ServicePriority `json:"priority"`
+	State    service.ServiceState    `json:"state"`
+	Deps     []string                `json:"dependencies"`
+	Optional []string                `json:"optional,omitempty"`
+	Weak     []string                `json:"weak,omitempty"`
+	After    []string                `json:"after,omitempty"`
+}
+
+// graphResponse 依赖图响应
+type graphResponse struct {
+	Nodes  []graphNode `json:"nodes"`
+	Levels [][]string  `json:"levels"`
+}
+
+func (h *Handler) handleGraph(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	graph := h.group.DependencyGraph()
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		graph.WriteDOT(w, service.WithServiceGroup(h.group))
+		return
+	case "mermaid":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		graph.WriteMermaid(w, service.WithServiceGroup(h.group))
+		return
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("unknown format %q", format),
+		})
+		return
+	}
+
+	levels, err := graph.GetStartLevels()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	states := h.group.GetServiceStates()
+	resp := graphResponse{Levels: levels}
+	for _, node := range graph.Nodes() {
+		deps := node.Deps
+		if deps == nil {
+			deps = []string{}
+		}
+		resp.Nodes = append(resp.Nodes, graphNode{
+			Name:     node.Name,
+			Priority: node.Priority,
+			State:    states[node.Name],
+			Deps:     deps,
+			Optional: node.Optional,
+			Weak:     node.Weak,
+			After:    node.After,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// eventResponse 单个事件
+type eventResponse struct {
+	Service  string                 `json:"service"`
+	Type     service.EventType      `json:"type"`
+	State    service.ServiceState   `json:"state"`
+	Time     time.Time              `json:"time"`
+	Error    string                 `json:"error,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// handleEvents 返回事件历史，例如 /api/events?service=api&limit=50
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := service.EventFilter{
+		Services: query["service"],
+	}
+	for _, t := range query["type"] {
+		filter.Types = append(filter.Types, service.EventType(t))
+	}
+	switch query.Get("errors") {
+	case "":
+	case "true":
+		filter.Errors = service.ErrorPresent
+	case "false":
+		filter.Errors = service.ErrorAbsent
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "errors must be true or false",
+		})
+		return
+	}
+
+	events := h.group.EventHistory(filter)
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("invalid limit %q", v),
+			})
+			return
+		}
+		if len(events) > limit {
+			events = events[len(events)-limit:]
+		}
+	}
+
+	resp := make([]eventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, eventResponse{
+			Service:  e.ServiceName,
+			Type:     e.EventType,
+			State:    e.State,
+			Time:     e.Time,
+			Error:    errorString(e.Error),
+			Metadata: eventMetadata(e.Metadata),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// eventMetadata 转换事件元数据，时长以字符串形式输出
+func eventMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		if d, ok := v.(time.Duration); ok {
+			v = d.String()
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// handleBoot 返回最近一次启动的报告，例如 /api/boot?format=chrome
+func (h *Handler) handleBoot(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	report := h.group.BootReport()
+	if report == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "service group has not been started",
+		})
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, report)
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		report.WriteText(w)
+	case "chrome":
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		report.WriteChromeTrace(w)
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("unknown format %q", format),
+		})
+	}
+}
+
+// handleServiceAction 处理 /api/services/{name}/{action}
+func (h *Handler) handleServiceAction(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/services/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	name, action := parts[0], parts[1]
+
+	var do func(context.Context, string) error
+	switch action {
+	case "start":
+		do = h.group.StartService
+	case "stop":
+		do = h.group.StopService
+	case "restart":
+		do = h.group.RestartService
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.actionTimeout)
+	defer cancel()
+
+	if err := do(ctx, name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	svc, err := h.group.GetService(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"service": name,
+		"action":  action,
+		"state":   svc.State(),
+	})
+}
+
+// allowMethod 检查请求方法，不允许时返回 405
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+		"error": fmt.Sprintf("method %s not allowed", r.Method),
+	})
+	return false
+}
+
+// writeError 根据错误码写入错误响应
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+
+	var se *service.ServiceError
+	if errors.As(err, &se) {
+		switch se.Code {
+		case service.ErrServiceNotFound:
+			code = http.StatusNotFound
+		case service.ErrInvalidState, service.ErrDependencyFailed:
+			code = http.StatusConflict
+		case service.ErrStartupTimeout, service.ErrShutdownTimeout:
+			code = http.StatusGatewayTimeout
+		}
+	}
+
+	writeJSON(w, code, map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// errorString 返回错误信息，nil 时返回空字符串
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,268 @@
+package httpadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkit/service"
+)
+
+// probedService 就绪与存活结果可控的服务，记录 HealthCheck 的调用次数
+type probedService struct {
+	*service.BaseService
+	notReady     atomic.Bool
+	notLive      atomic.Bool
+	healthChecks atomic.Int64
+}
+
+func (s *probedService) HealthCheck(ctx context.Context) error {
+	s.healthChecks.Add(1)
+	return s.BaseService.HealthCheck(ctx)
+}
+
+func (s *probedService) ReadinessCheck(context.Context) error {
+	if s.notReady.Load() {
+		return errors.New("warming up")
+	}
+	return nil
+}
+
+func (s *probedService) LivenessCheck(context.Context) error {
+	if s.notLive.Load() {
+		return errors.New("deadlocked")
+	}
+	return nil
+}
+
+// newTestHandler 创建包含 db 和依赖它的 api 两个服务的服务组及其处理器
+func newTestHandler(t *testing.T, start bool) (*Handler, *service.ServiceGroup, *probedService) {
+	t.Helper()
+	sg := service.NewServiceGroup(context.Background(), service.ServiceGroupOptions{
+		Logger:       service.NewLogger(slog.NewTextHandler(io.Discard, nil)),
+		StartTimeout: 5 * time.Second,
+		StopTimeout:  5 * time.Second,
+	})
+	api := &probedService{BaseService: service.NewBaseService("api", []string{"db"})}
+	if err := sg.Add(service.NewBaseService("db", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sg.Add(api); err != nil {
+		t.Fatal(err)
+	}
+	if start {
+		if err := sg.Start(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		sg.GracefulStop(context.Background())
+		sg.Events().Close()
+	})
+	return New(sg), sg, api
+}
+
+// serve 发送请求并返回响应
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestProbeEndpoints(t *testing.T) {
+	h, _, api := newTestHandler(t, true)
+
+	tests := []struct {
+		name              string
+		notReady, notLive bool
+		healthz, readyz   int
+		livez             int
+	}{
+		{"healthy", false, false, http.StatusOK, http.StatusOK, http.StatusOK},
+		{"not ready", true, false, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+		{"not live", false, true, http.StatusServiceUnavailable, http.StatusOK, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.notReady.Store(tt.notReady)
+			api.notLive.Store(tt.notLive)
+			for path, want := range map[string]int{"/healthz": tt.healthz, "/readyz": tt.readyz, "/livez": tt.livez} {
+				rec := serve(h, http.MethodGet, path)
+				if rec.Code != want {
+					t.Errorf("GET %s = %d, want %d: %s", path, rec.Code, want, rec.Body)
+				}
+				var resp probeResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("GET %s: %v", path, err)
+				}
+				if got := resp.Services["api"]; got.Ready == tt.notReady || got.Live == tt.notLive {
+					t.Errorf("GET %s: api probe = %+v", path, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProbeEndpointsNotStarted(t *testing.T) {
+	h, _, _ := newTestHandler(t, false)
+
+	if rec := serve(h, http.MethodGet, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if rec := serve(h, http.MethodGet, "/livez"); rec.Code != http.StatusOK {
+		t.Errorf("GET /livez = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(h, http.MethodPost, "/healthz"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /healthz = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+// 每个 /healthz 请求只对每个服务执行一次健康检查
+func TestHealthzProbesOnce(t *testing.T) {
+	h, sg, api := newTestHandler(t, true)
+	before, err := sg.GetServiceMetrics("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := api.healthChecks.Load()
+
+	if rec := serve(h, http.MethodGet, "/healthz"); rec.Code != http.StatusOK {
+		t.Fatalf("GET /healthz = %d: %s", rec.Code, rec.Body)
+	}
+
+	if got := api.healthChecks.Load() - calls; got != 1 {
+		t.Errorf("HealthCheck called %d times, want 1", got)
+	}
+	after, err := sg.GetServiceMetrics("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := after.HealthCheckCount - before.HealthCheckCount; got != 1 {
+		t.Errorf("HealthCheckCount increased by %d, want 1", got)
+	}
+}
+
+func TestServiceActions(t *testing.T) {
+	h, _, api := newTestHandler(t, true)
+
+	rec := serve(h, http.MethodGet, "/api/services/api/restart")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET restart = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := rec.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow = %q, want %q", allow, http.MethodPost)
+	}
+
+	for _, target := range []string{"/api/services/missing/restart", "/api/services/api/reload", "/api/services/api"} {
+		if rec := serve(h, http.MethodPost, target); rec.Code != http.StatusNotFound {
+			t.Errorf("POST %s = %d, want %d", target, rec.Code, http.StatusNotFound)
+		}
+	}
+
+	if rec := serve(h, http.MethodPost, "/api/services/api/stop"); rec.Code != http.StatusOK {
+		t.Fatalf("POST stop = %d: %s", rec.Code, rec.Body)
+	}
+	if state := api.State(); state != service.StateStopped {
+		t.Fatalf("api is %s after stop", state)
+	}
+
+	rec = serve(h, http.MethodPost, "/api/services/api/restart")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST restart = %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Service string               `json:"service"`
+		Action  string               `json:"action"`
+		State   service.ServiceState `json:"state"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Service != "api" || resp.Action != "restart" || resp.State != service.StateRunning {
+		t.Errorf("restart response = %+v", resp)
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	h, _, _ := newTestHandler(t, true)
+
+	rec := serve(h, http.MethodGet, "/api/graph")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/graph = %d: %s", rec.Code, rec.Body)
+	}
+	var graph graphResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 2 || len(graph.Levels) != 2 || graph.Levels[0][0] != "db" {
+		t.Errorf("graph = %+v", graph)
+	}
+
+	tests := []struct {
+		format      string
+		code        int
+		contentType string
+		contains    string
+	}{
+		{"json", http.StatusOK, "application/json", `"levels"`},
+		{"dot", http.StatusOK, "text/vnd.graphviz", "digraph services {"},
+		{"mermaid", http.StatusOK, "text/plain", "flowchart LR"},
+		{"svg", http.StatusBadRequest, "application/json", "unknown format"},
+	}
+	for _, tt := range tests {
+		rec := serve(h, http.MethodGet, "/api/graph?format="+tt.format)
+		if rec.Code != tt.code {
+			t.Errorf("format %s: code = %d, want %d", tt.format, rec.Code, tt.code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("format %s: Content-Type = %q, want %s", tt.format, ct, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.contains) {
+			t.Errorf("format %s: body does not contain %q:\n%s", tt.format, tt.contains, rec.Body)
+		}
+	}
+}
+
+func TestBootFormats(t *testing.T) {
+	h, sg, _ := newTestHandler(t, false)
+
+	if rec := serve(h, http.MethodGet, "/api/boot"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/boot before Start = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if err := sg.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format      string
+		code        int
+		contentType string
+		contains    string
+	}{
+		{"", http.StatusOK, "application/json", `"criticalPath"`},
+		{"json", http.StatusOK, "application/json", `"criticalPath"`},
+		{"text", http.StatusOK, "text/plain", "Critical chain:"},
+		{"chrome", http.StatusOK, "application/json", `"traceEvents"`},
+		{"svg", http.StatusBadRequest, "application/json", "unknown format"},
+	}
+	for _, tt := range tests {
+		rec := serve(h, http.MethodGet, "/api/boot?format="+tt.format)
+		if rec.Code != tt.code {
+			t.Errorf("format %q: code = %d, want %d", tt.format, rec.Code, tt.code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("format %q: Content-Type = %q, want %s", tt.format, ct, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.contains) {
+			t.Errorf("format %q: body does not contain %q:\n%s", tt.format, tt.contains, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Provider 可选接口，服务进入 Running 状态后向依赖方导出值
+type Provider interface {
+	// ProvidedType 返回导出值的类型，nil 表示不导出任何值
+	ProvidedType() reflect.Type
+	// Provide 返回导出的值
+	Provide() any
+}
+
+// injectTag 结构体字段注入标签，例如：
+//
+//	type APIService struct {
+//		*service.BaseService
+//		DB *Database `inject:"database"`
+//	}
+const injectTag = "inject"
+
+// injectorKey 上下文中注入值的键
+type injectorKey struct{}
+
+// Provide 返回一个服务选项，声明 BaseService 导出的值
+func Provide[T any](fn func() T) ServiceOption {
+	return func(bs *BaseService) {
+		bs.providedType = reflect.TypeOf((*T)(nil)).Elem()
+		bs.provideFunc = func() any { return fn() }
+	}
+}
+
+// Resolve 从 Init/Start 的上下文中获取依赖服务导出的值
+func Resolve[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+
+	values, _ := ctx.Value(injectorKey{}).(map[string]any)
+	value, ok := values[name]
+	if !ok {
+		return zero, &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: fmt.Sprintf("no value provided by dependency %s", name),
+		}
+	}
+
+	v, ok := value.(T)
+	if !ok {
+		return zero, &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: fmt.Sprintf("dependency %s provides %T, not %s", name, value, reflect.TypeOf((*T)(nil)).Elem()),
+		}
+	}
+	return v, nil
+}
+
+// injectionTargets 通过 inject 标签收集服务需要注入的字段类型
+func injectionTargets(s Service) map[string]reflect.Type {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	var targets map[string]reflect.Type
+	t := v.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup(injectTag)
+		if !ok || name == "" || !field.IsExported() {
+			continue
+		}
+		if targets == nil {
+			targets = make(map[string]reflect.Type)
+		}
+		targets[name] = field.Type
+	}
+	return targets
+}
+
+// providedType 获取服务导出值的类型
+func providedType(s Service) reflect.Type {
+	if p, ok := s.(Provider); ok {
+		return p.ProvidedType()
+	}
+	return nil
+}
+
+// inject 将已运行依赖导出的值写入服务的注入字段，并返回携带这些值的上下文
+func (sg *ServiceGroup) inject(ctx context.Context, s Service) (context.Context, error) {
+	node, ok := sg.depGraph.GetNode(s.Name())
+	if !ok {
+		return ctx, nil
+	}
+	deps := append(node.hardDeps(), node.Weak...)
+
+	values := make(map[string]any)
+	for _, dep := range deps {
+		svc, ok := sg.services.Load(dep)
+		if !ok {
+			continue
+		}
+		p, ok := svc.(Provider)
+		if !ok || p.ProvidedType() == nil || svc.(Service).State() != StateRunning {
+			continue
+		}
+		values[dep] = p.Provide()
+	}
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	// 设置 inject 标签字段
+	if targets := injectionTargets(s); len(targets) > 0 {
+		elem := reflect.ValueOf(s).Elem()
+		t := elem.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := t.Field(i).Tag.Lookup(injectTag)
+			if !ok {
+				continue
+			}
+			value, ok := values[name]
+			if !ok || value == nil {
+				continue
+			}
+			rv := reflect.ValueOf(value)
+			field := elem.Field(i)
+			if !field.CanSet() || !rv.Type().AssignableTo(field.Type()) {
+				return ctx, &ServiceError{
+					Code:    ErrDependencyFailed,
+					Message: fmt.Sprintf("cannot inject %s from %s into field %s of service %s", rv.Type(), name, t.Field(i).Name, s.Name()),
+				}
+			}
+			field.Set(rv)
+		}
+	}
+
+	return context.WithValue(ctx, injectorKey{}, values), nil
+}
+
+// checkInjections 检查节点的注入声明与已注册的提供者是否匹配
+func (dg *DependencyGraph) checkInjections(node *ServiceNode) error {
+	// 检查节点需要注入的值
+	for name, want := range node.Injects {
+		if kind, declared := node.DependencyKind(name); !declared || kind == DependencyAfter {
+			return &ServiceError{
+				Code:    ErrDependencyFailed,
+				Message: fmt.Sprintf("service %s injects %s but does not declare it as a dependency", node.Name, name),
+			}
+		}
+
+		if provider, exists := dg.nodes[name]; exists {
+			if err := checkProvided(provider, node.Name, want); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 检查已注册服务对该节点的注入需求
+	for _, other := range dg.nodes {
+		if want, ok := other.Injects[node.Name]; ok {
+			if err := checkProvided(node, other.Name, want); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkProvided 检查提供者导出的类型能否注入到依赖方
+func checkProvided(provider *ServiceNode, consumer string, want reflect.Type) error {
+	if provider.Provides == nil {
+		return &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: fmt.Sprintf("service %s requires a value from %s, but %s does not provide one", consumer, provider.Name, provider.Name),
+		}
+	}
+	if !provider.Provides.AssignableTo(want) {
+		return &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: fmt.Sprintf("service %s requires %s from %s, but %s provides %s", consumer, want, provider.Name, provider.Name, provider.Provides),
+		}
+	}
+	return nil
+}
@@ -4,47 +4,173 @@ import (
 	"log/slog"
 )
 
-// Logger 日志接口
+// Logger 结构化日志接口
+// msg 为日志消息，args 为交替的键值对或 slog.Attr，语义与 slog.Logger 的同名方法相同
 type Logger interface {
-	Debug(format string, args ...any)
-	Info(format string, args ...any)
-	Warn(format string, args ...any)
-	Error(format string, args ...any)
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
 }
 
-// logAdapter 默认日志记录器
+// logAdapter 基于 slog 的日志记录器
 type logAdapter struct {
 	logger *slog.Logger
 }
 
-// 全局日志实例
-var defaultLogger Logger = &logAdapter{logger: slog.Default()}
+// defaultLogger 未配置日志器时使用的日志器，每次调用时读取 slog.Default()
+func defaultLogger() Logger {
+	return &logAdapter{logger: slog.Default()}
+}
 
-// SetLogger 设置日志实现
-func (bs *BaseService) SetLogger(l Logger) *BaseService {
-	if l != nil {
-		defaultLogger = l
+// NewLogger 基于 slog.Handler 创建日志记录器
+func NewLogger(handler slog.Handler) Logger {
+	return &logAdapter{logger: slog.New(handler)}
+}
+
+// WithLogger 为服务单独设置日志器，覆盖从服务组继承的日志器
+func WithLogger(l Logger) ServiceOption {
+	return func(bs *BaseService) {
+		bs.logger = l
 	}
+}
+
+// SetLogger 为服务单独设置日志器，覆盖从服务组继承的日志器，只影响当前服务
+func (bs *BaseService) SetLogger(l Logger) *BaseService {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.logger = l
 	return bs
 }
 
-// GetLogger 获取日志器
+// GetLogger 获取带有服务名和当前状态属性的日志器
+// 依次使用服务自身的日志器、所属服务组的日志器和基于 slog.Default() 的默认日志器
 func (bs *BaseService) GetLogger() Logger {
-	return defaultLogger
+	bs.mu.RLock()
+	l := bs.logger
+	if l == nil {
+		l = bs.groupLogger
+	}
+	bs.mu.RUnlock()
+
+	if l == nil {
+		l = defaultLogger()
+	}
+	return newServiceLogger(l, bs)
+}
+
+// inheritLogger 由服务组在添加服务时调用，设置继承的日志器
+func (bs *BaseService) inheritLogger(l Logger) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.groupLogger = l
+}
+
+// loggerInheritor 可以从服务组继承日志器的服务，嵌入 BaseService 的服务自动实现
+type loggerInheritor interface {
+	inheritLogger(l Logger)
+}
+
+func (l *logAdapter) Debug(msg string, args ...any) {
+	l.logger.Debug(msg, args...)
+}
+
+func (l *logAdapter) Info(msg string, args ...any) {
+	l.logger.Info(msg, args...)
+}
+
+func (l *logAdapter) Warn(msg string, args ...any) {
+	l.logger.Warn(msg, args...)
+}
+
+func (l *logAdapter) Error(msg string, args ...any) {
+	l.logger.Error(msg, args...)
+}
+
+// With 返回附加了属性的子日志器
+func (l *logAdapter) With(args ...any) Logger {
+	return &logAdapter{logger: l.logger.With(args...)}
+}
+
+// withAttrs 返回附加了属性的日志器
+// 实现了 With(args ...any) Logger 的日志器直接使用其 With，其他实现在每次调用时追加属性
+func withAttrs(l Logger, args ...any) Logger {
+	if len(args) == 0 {
+		return l
+	}
+	if w, ok := l.(interface{ With(args ...any) Logger }); ok {
+		return w.With(args...)
+	}
+	return &attrLogger{base: l, attrs: args}
+}
+
+// attrLogger 为不支持 With 的日志器追加固定属性
+type attrLogger struct {
+	base  Logger
+	attrs []any
+}
+
+func (l *attrLogger) args(args []any) []any {
+	return append(append(make([]any, 0, len(l.attrs)+len(args)), l.attrs...), args...)
+}
+
+func (l *attrLogger) Debug(msg string, args ...any) {
+	l.base.Debug(msg, l.args(args)...)
+}
+
+func (l *attrLogger) Info(msg string, args ...any) {
+	l.base.Info(msg, l.args(args)...)
+}
+
+func (l *attrLogger) Warn(msg string, args ...any) {
+	l.base.Warn(msg, l.args(args)...)
+}
+
+func (l *attrLogger) Error(msg string, args ...any) {
+	l.base.Error(msg, l.args(args)...)
+}
+
+// With 返回附加了更多属性的子日志器
+func (l *attrLogger) With(args ...any) Logger {
+	return &attrLogger{base: l.base, attrs: l.args(args)}
+}
+
+// serviceLogger 服务的子日志器，附加服务名，并在每次输出时附加服务的当前状态
+type serviceLogger struct {
+	base    Logger
+	service Service
+}
+
+// newServiceLogger 创建服务的子日志器
+func newServiceLogger(base Logger, s Service) Logger {
+	return &serviceLogger{
+		base:    withAttrs(base, "service", s.Name()),
+		service: s,
+	}
+}
+
+// args 追加服务的当前状态
+func (l *serviceLogger) args(args []any) []any {
+	return append(append(make([]any, 0, len(args)+2), args...), "state", l.service.State())
+}
+
+func (l *serviceLogger) Debug(msg string, args ...any) {
+	l.base.Debug(msg, l.args(args)...)
 }
 
-func (l *logAdapter) Debug(format string, args ...any) {
-	l.logger.Debug(format, args...)
+func (l *serviceLogger) Info(msg string, args ...any) {
+	l.base.Info(msg, l.args(args)...)
 }
 
-func (l *logAdapter) Info(format string, args ...any) {
-	l.logger.Info(format, args...)
+func (l *serviceLogger) Warn(msg string, args ...any) {
+	l.base.Warn(msg, l.args(args)...)
 }
 
-func (l *logAdapter) Warn(format string, args ...any) {
-	l.logger.Warn(format, args...)
+func (l *serviceLogger) Error(msg string, args ...any) {
+	l.base.Error(msg, l.args(args)...)
 }
 
-func (l *logAdapter) Error(format string, args ...any) {
-	l.logger.Error(format, args...)
+// With 返回附加了更多属性的子日志器
+func (l *serviceLogger) With(args ...any) Logger {
+	return &serviceLogger{base: withAttrs(l.base, args...), service: l.service}
 }
@@ -0,0 +1,480 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Manifest 声明式的服务组定义
+//
+//	{
+//	  "name": "api",
+//	  "startTimeout": "30s",
+//	  "services": [
+//	    {"name": "db", "type": "postgres", "priority": 10, "config": {"dsn": "..."}},
+//	    {"name": "http", "type": "http", "dependencies": ["db"], "stopTimeout": "5s"}
+//	  ]
+//	}
+type Manifest struct {
+	Name                string        `json:"name,omitempty"`
+	StartTimeout        Duration      `json:"startTimeout,omitempty"`
+	StopTimeout         Duration      `json:"stopTimeout,omitempty"`
+	HealthCheckInterval Duration      `json:"healthCheckInterval,omitempty"`
+	MaxConcurrency      int           `json:"maxConcurrency,omitempty"`
+	Services            []ServiceSpec `json:"services"`
+}
+
+// ServiceSpec 清单中的单个服务定义
+type ServiceSpec struct {
+	Name         string           `json:"name"`
+	Type         string           `json:"type"` // 服务工厂的类型名
+	Dependencies []string         `json:"dependencies,omitempty"`
+	Optional     []string         `json:"optional,omitempty"`
+	Weak         []string         `json:"weak,omitempty"`
+	After        []string         `json:"after,omitempty"`
+	Priority     *ServicePriority `json:"priority,omitempty"` // nil 表示使用服务的默认优先级
+	StopTimeout  Duration         `json:"stopTimeout,omitempty"`
+	Config       json.RawMessage  `json:"config,omitempty"` // 服务自身的配置，原样保留
+}
+
+// Options 返回与清单声明一致的 BaseService 选项，必需依赖需作为 NewBaseService 的参数传入
+func (s ServiceSpec) Options() []ServiceOption {
+	var opts []ServiceOption
+	if s.Priority != nil {
+		opts = append(opts, WithPriority(*s.Priority))
+	}
+	if len(s.Optional) > 0 {
+		opts = append(opts, WithOptionalDependencies(s.Optional...))
+	}
+	if len(s.Weak) > 0 {
+		opts = append(opts, WithWeakDependencies(s.Weak...))
+	}
+	if len(s.After) > 0 {
+		opts = append(opts, WithAfter(s.After...))
+	}
+	if s.StopTimeout > 0 {
+		opts = append(opts, WithStopTimeout(time.Duration(s.StopTimeout)))
+	}
+	return opts
+}
+
+// DecodeConfig 将服务配置解码到 v，没有配置时不修改 v
+func (s ServiceSpec) DecodeConfig(v interface{}) error {
+	if !s.hasConfig() {
+		return nil
+	}
+	if err := json.Unmarshal(s.Config, v); err != nil {
+		return &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("invalid config for service %s", s.Name),
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// hasConfig 是否声明了配置
+func (s ServiceSpec) hasConfig() bool {
+	return len(s.Config) > 0 && !bytes.Equal(s.Config, []byte("null"))
+}
+
+// node 返回服务在依赖图中的节点
+func (s ServiceSpec) node() *ServiceNode {
+	node := &ServiceNode{
+		Name:     s.Name,
+		Priority: PriorityNormal,
+		Deps:     s.Dependencies,
+		Optional: s.Optional,
+		Weak:     s.Weak,
+		After:    s.After,
+	}
+	if s.Priority != nil {
+		node.Priority = *s.Priority
+	}
+	return node
+}
+
+// checkDependencies 检查工厂创建的服务声明的依赖是否与清单一致
+// 服务组根据服务自身声明的依赖构建依赖图，不一致时清单的校验结果就没有意义
+func (s ServiceSpec) checkDependencies(svc Service) error {
+	var optional, weak, after []string
+	if soft, ok := svc.(SoftDependencies); ok {
+		optional = soft.OptionalDependencies()
+		weak = soft.WeakDependencies()
+		after = soft.AfterDependencies()
+	}
+	kinds := []struct {
+		kind             DependencyKind
+		declared, actual []string
+	}{
+		{DependencyRequired, s.Dependencies, svc.Dependencies()},
+		{DependencyOptional, s.Optional, optional},
+		{DependencyWeak, s.Weak, weak},
+		{DependencyAfter, s.After, after},
+	}
+	for _, k := range kinds {
+		if !sameNames(k.declared, k.actual) {
+			return &ServiceError{
+				Code: ErrInvalidManifest,
+				Message: fmt.Sprintf("service %s declares %s dependencies %v, but the manifest declares %v",
+					s.Name, k.kind, k.actual, k.declared),
+			}
+		}
+	}
+	return nil
+}
+
+// sameNames 两组名称去重后是否相同，不考虑顺序
+func sameNames(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, name := range a {
+		set[name] = true
+	}
+	seen := make(map[string]bool, len(b))
+	for _, name := range b {
+		if !set[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return len(seen) == len(set)
+}
+
+// Duration 清单中的时长，可以写作 "30s"、"1m30s" 这样的字符串，或以秒为单位的数字
+type Duration time.Duration
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	case float64:
+		*d = Duration(v * float64(time.Second))
+	case nil:
+		*d = 0
+	default:
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	return nil
+}
+
+// ServiceFactory 根据清单中的定义创建服务
+// 返回的服务名称和各类依赖必须与清单声明一致，否则 Build 返回错误；
+// 基于 BaseService 的服务可以使用 NewBaseService(spec.Name, spec.Dependencies, spec.Options()...)
+type ServiceFactory func(spec ServiceSpec) (Service, error)
+
+// ManifestDecoder 将清单文件解码到 v，签名与 json.Unmarshal、yaml.Unmarshal、toml.Unmarshal 一致
+type ManifestDecoder func(data []byte, v interface{}) error
+
+// FactoryOption 注册服务工厂的选项
+type FactoryOption func(*factoryEntry)
+
+// WithConfigUpdate 创建服务后通过 Update 传入配置，而不是由工厂自行解码 spec.Config
+// newConfig 返回用于解码的配置指针，例如 func() interface{} { return &HTTPConfig{} }；
+// 为 nil 时传入解码后的通用值（map[string]interface{} 等）
+func WithConfigUpdate(newConfig func() interface{}) FactoryOption {
+	return func(f *factoryEntry) {
+		f.update = true
+		f.newConfig = newConfig
+	}
+}
+
+// factoryEntry 已注册的服务工厂
+type factoryEntry struct {
+	factory   ServiceFactory
+	update    bool
+	newConfig func() interface{}
+}
+
+// Registry 按类型名注册服务工厂，从清单构建服务组
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]*factoryEntry
+	decoders  map[string]ManifestDecoder
+}
+
+// NewRegistry 创建服务工厂注册表，内置 JSON 解码器
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]*factoryEntry),
+		decoders: map[string]ManifestDecoder{
+			"json": json.Unmarshal,
+		},
+	}
+}
+
+// Register 注册类型名对应的服务工厂，重复注册会覆盖之前的工厂
+func (r *Registry) Register(typeName string, factory ServiceFactory, opts ...FactoryOption) {
+	entry := &factoryEntry{factory: factory}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[typeName] = entry
+}
+
+// RegisterDecoder 注册清单格式的解码器，format 为文件扩展名（不含点），例如 "yaml"、"yml"、"toml"
+func (r *Registry) RegisterDecoder(format string, decode ManifestDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[strings.ToLower(format)] = decode
+}
+
+// Types 返回已注册的类型名
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.factories))
+	for typeName := range r.factories {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// LoadManifest 读取清单文件，按扩展名选择解码器
+func (r *Registry) LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("failed to read manifest %s", path),
+			Err:     err,
+		}
+	}
+	return r.ParseManifest(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ParseManifest 使用 format 对应的解码器解析清单，不认识的字段会报错
+func (r *Registry) ParseManifest(data []byte, format string) (*Manifest, error) {
+	format = strings.ToLower(format)
+	r.mu.RLock()
+	decode, ok := r.decoders[format]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("no decoder registered for manifest format %q", format),
+		}
+	}
+
+	// 其他格式先解码为通用值再转换为 JSON，由 JSON 标签统一处理字段名和时长
+	if format != "json" {
+		var raw map[string]interface{}
+		if err := decode(data, &raw); err != nil {
+			return nil, &ServiceError{Code: ErrInvalidManifest, Message: "failed to decode manifest", Err: err}
+		}
+		converted, err := json.Marshal(normalizeManifestValue(raw))
+		if err != nil {
+			return nil, &ServiceError{Code: ErrInvalidManifest, Message: "failed to decode manifest", Err: err}
+		}
+		data = converted
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, &ServiceError{Code: ErrInvalidManifest, Message: "failed to decode manifest", Err: err}
+	}
+	return &m, nil
+}
+
+// normalizeManifestValue 将 YAML 解码器产生的 map[interface{}]interface{} 转换为可编码为 JSON 的 map[string]interface{}
+func normalizeManifestValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeManifestValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeManifestValue(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeManifestValue(val)
+		}
+		return v
+	case []map[string]interface{}:
+		// TOML 的表数组
+		list := make([]interface{}, len(v))
+		for i, val := range v {
+			list[i] = normalizeManifestValue(val)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// Validate 在创建任何服务之前检查清单：服务名称为空或重复、类型未注册、循环依赖以及缺失的必需依赖
+func (r *Registry) Validate(m *Manifest) error {
+	var problems []string
+	graph := NewDependencyGraph()
+
+	r.mu.RLock()
+	for i, spec := range m.Services {
+		switch {
+		case spec.Name == "":
+			problems = append(problems, fmt.Sprintf("service #%d has no name", i))
+			continue
+		case graph.nodes[spec.Name] != nil:
+			problems = append(problems, fmt.Sprintf("duplicate service %s", spec.Name))
+			continue
+		}
+		if _, ok := r.factories[spec.Type]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown type %q for service %s", spec.Type, spec.Name))
+		}
+		// 直接写入节点，循环依赖交给 Analyze 统一报告
+		graph.nodes[spec.Name] = spec.node()
+	}
+	r.mu.RUnlock()
+
+	if len(problems) > 0 {
+		return &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: "invalid manifest: " + strings.Join(problems, "; "),
+		}
+	}
+	return graph.Analyze().Err()
+}
+
+// Build 校验清单并创建服务组，服务按清单中的顺序添加，不会启动任何服务
+// opts 为服务组的基础配置，清单中声明的名称、超时和并发数会覆盖对应的字段
+func (r *Registry) Build(ctx context.Context, m *Manifest, opts ...ServiceGroupOptions) (*ServiceGroup, error) {
+	if err := r.Validate(m); err != nil {
+		return nil, err
+	}
+
+	services := make([]Service, 0, len(m.Services))
+	for _, spec := range m.Services {
+		s, err := r.create(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+
+	options := DefaultServiceGroupOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	m.apply(&options)
+
+	sg := NewServiceGroup(ctx, options)
+	for _, s := range services {
+		if err := sg.Add(s); err != nil {
+			sg.cancel()
+			return nil, err
+		}
+	}
+	return sg, nil
+}
+
+// Load 读取清单文件并创建服务组
+func (r *Registry) Load(ctx context.Context, path string, opts ...ServiceGroupOptions) (*ServiceGroup, error) {
+	m, err := r.LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.Build(ctx, m, opts...)
+}
+
+// create 使用注册的工厂创建服务，并按需通过 Update 传入配置
+func (r *Registry) create(ctx context.Context, spec ServiceSpec) (Service, error) {
+	r.mu.RLock()
+	entry := r.factories[spec.Type]
+	r.mu.RUnlock()
+	if entry == nil {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("unknown type %q for service %s", spec.Type, spec.Name),
+		}
+	}
+
+	s, err := entry.factory(spec)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("failed to create service %s of type %s", spec.Name, spec.Type),
+			Err:     err,
+		}
+	}
+	if s == nil || s.Name() != spec.Name {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("factory for type %s did not return service %s", spec.Type, spec.Name),
+		}
+	}
+	if err := spec.checkDependencies(s); err != nil {
+		return nil, err
+	}
+
+	if !entry.update || !spec.hasConfig() {
+		return s, nil
+	}
+
+	var config interface{}
+	if entry.newConfig != nil {
+		config = entry.newConfig()
+		if err := spec.DecodeConfig(config); err != nil {
+			return nil, err
+		}
+	} else if err := spec.DecodeConfig(&config); err != nil {
+		return nil, err
+	}
+	if err := s.Update(ctx, config); err != nil {
+		return nil, &ServiceError{
+			Code:    ErrInvalidManifest,
+			Message: fmt.Sprintf("failed to apply config to service %s", spec.Name),
+			Err:     err,
+		}
+	}
+	return s, nil
+}
+
+// apply 用清单中声明的字段覆盖服务组配置
+func (m *Manifest) apply(options *ServiceGroupOptions) {
+	if m.Name != "" {
+		options.Name = m.Name
+	}
+	if m.StartTimeout > 0 {
+		options.StartTimeout = time.Duration(m.StartTimeout)
+	}
+	if m.StopTimeout > 0 {
+		options.StopTimeout = time.Duration(m.StopTimeout)
+	}
+	if m.HealthCheckInterval > 0 {
+		options.HealthCheckInterval = time.Duration(m.HealthCheckInterval)
+	}
+	if m.MaxConcurrency > 0 {
+		options.MaxConcurrency = m.MaxConcurrency
+	}
+}
@@ -2,35 +2,109 @@ package service
 
 import (
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
-// ServiceMetrics 服务指标
+// ServiceMetrics 服务指标快照
+// 由 GetMetrics 等方法返回的值是获取时刻的副本，之后不会再被修改
 type ServiceMetrics struct {
-	StartTime         time.Time
-	RestartCount      atomic.Int64
-	LastError         error
-	LastErrorTime     time.Time
 	State             ServiceState
-	HealthCheckCount  atomic.Int64
-	HealthCheckErrors atomic.Int64
-	LastHealthCheck   time.Time
-	TotalUptime       time.Duration
-	LastStateChange   time.Time
+	StartTime         time.Time     // 最近一次进入 Running 状态的时间
+	StartCount        int64         // 进入 Running 状态的次数
+	StopCount         int64         // 进入 Stopped 状态的次数
+	RestartCount      int64         // 重启次数
+	ErrorCount        int64         // 生命周期步骤失败的次数
+	LastError         error         // 最近一次生命周期步骤失败的错误
+	LastErrorTime     time.Time     // 最近一次生命周期步骤失败的时间
+	HealthCheckCount  int64         // 健康检查次数
+	HealthCheckErrors int64         // 健康检查失败次数
+	LastHealthCheck   time.Time     // 最近一次健康检查的时间
+	TotalUptime       time.Duration // 累计处于 Running 状态的时长，包含当前这一次运行
+	LastStateChange   time.Time     // 最近一次状态变更的时间
+
+	// 各生命周期步骤（Init、Start、Stop、Restart、Update、HealthCheck）的耗时统计
+	Latency map[EventType]LatencyStats
+	// 各滚动窗口内处于 Running 状态的时间占比，服务注册不足一个窗口时按注册以来的时长计算
+	Availability map[time.Duration]float64
+}
+
+// LatencyStats 生命周期步骤的耗时统计
+type LatencyStats struct {
+	Count       uint64
+	Sum         time.Duration
+	Min         time.Duration
+	Max         time.Duration
+	Mean        time.Duration
+	Buckets     []float64                 // 直方图桶上界（秒）
+	Counts      []uint64                  // 每个桶的累计计数
+	Percentiles map[float64]time.Duration // 按 MetricsOptions.Percentiles 估算的分位数
+}
+
+// MetricsOptions 指标收集配置
+type MetricsOptions struct {
+	LatencyBuckets      []float64       // 耗时直方图桶上界（秒）
+	Percentiles         []float64       // 需要估算的耗时分位数，取值 [0, 1]
+	AvailabilityWindows []time.Duration // 计算可用率的滚动时间窗口
+}
+
+// DefaultMetricsOptions 默认指标收集配置
+var DefaultMetricsOptions = MetricsOptions{
+	LatencyBuckets:      DefaultLatencyBuckets,
+	Percentiles:         []float64{0.5, 0.9, 0.99},
+	AvailabilityWindows: []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour},
+}
+
+// normalize 为未设置的配置项填充默认值
+func (o MetricsOptions) normalize() MetricsOptions {
+	if len(o.LatencyBuckets) == 0 {
+		o.LatencyBuckets = DefaultMetricsOptions.LatencyBuckets
+	}
+	if len(o.Percentiles) == 0 {
+		o.Percentiles = DefaultMetricsOptions.Percentiles
+	}
+	if len(o.AvailabilityWindows) == 0 {
+		o.AvailabilityWindows = DefaultMetricsOptions.AvailabilityWindows
+	}
+	return o
 }
 
 // MetricsCollector 指标收集器
 type MetricsCollector struct {
-	mu      sync.RWMutex
-	metrics map[string]*ServiceMetrics
+	mu        sync.RWMutex
+	options   MetricsOptions
+	maxWindow time.Duration
+	services  map[string]*serviceRecord
+}
+
+// serviceRecord 单个服务的指标，所有字段由 mu 保护
+type serviceRecord struct {
+	mu         sync.Mutex
+	metrics    ServiceMetrics
+	registered time.Time
+	uptime     []timeRange // 已结束的运行区间，只保留最大可用率窗口内的部分
+	latency    map[EventType]*histogram
+}
+
+// timeRange 时间区间
+type timeRange struct {
+	from, to time.Time
 }
 
 // NewMetricsCollector 创建新的指标收集器
-func NewMetricsCollector() *MetricsCollector {
-	return &MetricsCollector{
-		metrics: make(map[string]*ServiceMetrics),
+func NewMetricsCollector(opts ...MetricsOptions) *MetricsCollector {
+	options := DefaultMetricsOptions
+	if len(opts) > 0 {
+		options = opts[0].normalize()
+	}
+
+	mc := &MetricsCollector{
+		options:  options,
+		services: make(map[string]*serviceRecord),
 	}
+	for _, w := range options.AvailabilityWindows {
+		mc.maxWindow = max(mc.maxWindow, w)
+	}
+	return mc
 }
 
 // RegisterService 注册服务到指标收集器
@@ -38,104 +112,257 @@ func (mc *MetricsCollector) RegisterService(serviceName string) {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 
-	if _, exists := mc.metrics[serviceName]; !exists {
-		mc.metrics[serviceName] = &ServiceMetrics{
-			LastStateChange: time.Now(),
+	if _, exists := mc.services[serviceName]; !exists {
+		now := time.Now()
+		mc.services[serviceName] = &serviceRecord{
+			metrics: ServiceMetrics{
+				LastStateChange: now,
+			},
+			registered: now,
+			latency:    make(map[EventType]*histogram),
 		}
 	}
 }
 
-// RecordStart 记录服务启动
-func (mc *MetricsCollector) RecordStart(serviceName string) {
+// UnregisterService 从指标收集器中移除服务
+func (mc *MetricsCollector) UnregisterService(serviceName string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.services, serviceName)
+}
+
+// record 查找服务的指标记录
+func (mc *MetricsCollector) record(serviceName string) (*serviceRecord, bool) {
 	mc.mu.RLock()
-	metrics, exists := mc.metrics[serviceName]
-	mc.mu.RUnlock()
+	defer mc.mu.RUnlock()
+
+	r, exists := mc.services[serviceName]
+	return r, exists
+}
 
-	if exists {
-		metrics.StartTime = time.Now()
-		metrics.State = StateRunning
-		metrics.LastStateChange = time.Now()
+// update 在持有服务记录锁的情况下修改指标
+func (mc *MetricsCollector) update(serviceName string, fn func(m *ServiceMetrics)) {
+	r, exists := mc.record(serviceName)
+	if !exists {
+		return
 	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.metrics)
 }
 
-// RecordStop 记录服务停止
-func (mc *MetricsCollector) RecordStop(serviceName string) {
-	mc.mu.RLock()
-	metrics, exists := mc.metrics[serviceName]
-	mc.mu.RUnlock()
+// RecordLatency 记录生命周期步骤的耗时，phase 为步骤对应的事件类型
+func (mc *MetricsCollector) RecordLatency(serviceName string, phase EventType, d time.Duration) {
+	r, exists := mc.record(serviceName)
+	if !exists {
+		return
+	}
 
-	if exists {
-		metrics.State = StateStopped
-		metrics.LastStateChange = time.Now()
-		metrics.TotalUptime += time.Since(metrics.StartTime)
+	r.mu.Lock()
+	h, ok := r.latency[phase]
+	if !ok {
+		h = newHistogram(mc.options.LatencyBuckets)
+		r.latency[phase] = h
 	}
+	r.mu.Unlock()
+
+	h.observe(d.Seconds())
 }
 
-// RecordRestart 记录服务重启
-func (mc *MetricsCollector) RecordRestart(serviceName string) {
-	mc.mu.RLock()
-	metrics, exists := mc.metrics[serviceName]
-	mc.mu.RUnlock()
+// RecordStateChange 记录服务状态变更，维护启动时间、启停次数和累计运行时长
+// ServiceGroup 会对其管理的服务的每次状态变更自动调用
+func (mc *MetricsCollector) RecordStateChange(serviceName string, to ServiceState, at time.Time) {
+	r, exists := mc.record(serviceName)
+	if !exists {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	if exists {
-		metrics.RestartCount.Add(1)
-		metrics.StartTime = time.Now()
-		metrics.LastStateChange = time.Now()
+	m := &r.metrics
+	if m.State == to {
+		return
+	}
+	// 离开 Running 状态时累计本次运行时长，并保留运行区间用于计算可用率
+	if m.State == StateRunning && !m.StartTime.IsZero() && at.After(m.StartTime) {
+		m.TotalUptime += at.Sub(m.StartTime)
+		r.uptime = append(r.uptime, timeRange{from: m.StartTime, to: at})
+		r.pruneUptime(at.Add(-mc.maxWindow))
 	}
+	switch to {
+	case StateRunning:
+		m.StartTime = at
+		m.StartCount++
+	case StateStopped:
+		m.StopCount++
+	}
+	m.State = to
+	m.LastStateChange = at
 }
 
-// RecordError 记录服务错误
-func (mc *MetricsCollector) RecordError(serviceName string, err error) {
-	mc.mu.RLock()
-	metrics, exists := mc.metrics[serviceName]
-	mc.mu.RUnlock()
+// pruneUptime 丢弃在 cutoff 之前结束的运行区间
+func (r *serviceRecord) pruneUptime(cutoff time.Time) {
+	i := 0
+	for i < len(r.uptime) && !r.uptime[i].to.After(cutoff) {
+		i++
+	}
+	if i > 0 {
+		r.uptime = append(r.uptime[:0], r.uptime[i:]...)
+	}
+}
+
+// RecordStart 记录服务进入 Running 状态
+func (mc *MetricsCollector) RecordStart(serviceName string) {
+	mc.RecordStateChange(serviceName, StateRunning, time.Now())
+}
+
+// RecordStop 记录服务进入 Stopped 状态
+func (mc *MetricsCollector) RecordStop(serviceName string) {
+	mc.RecordStateChange(serviceName, StateStopped, time.Now())
+}
+
+// RecordRestart 记录服务重启
+func (mc *MetricsCollector) RecordRestart(serviceName string) {
+	mc.update(serviceName, func(m *ServiceMetrics) {
+		m.RestartCount++
+	})
+}
 
-	if exists {
-		metrics.LastError = err
-		metrics.LastErrorTime = time.Now()
-		metrics.State = StateError
-		metrics.LastStateChange = time.Now()
+// RecordError 记录服务生命周期步骤失败，服务状态以 RecordStateChange 为准
+func (mc *MetricsCollector) RecordError(serviceName string, err error) {
+	if err == nil {
+		return
 	}
+	mc.update(serviceName, func(m *ServiceMetrics) {
+		m.ErrorCount++
+		m.LastError = err
+		m.LastErrorTime = time.Now()
+	})
 }
 
 // RecordHealthCheck 记录健康检查
 func (mc *MetricsCollector) RecordHealthCheck(serviceName string, err error) {
-	mc.mu.RLock()
-	metrics, exists := mc.metrics[serviceName]
-	mc.mu.RUnlock()
-
-	if exists {
-		metrics.HealthCheckCount.Add(1)
-		metrics.LastHealthCheck = time.Now()
+	mc.update(serviceName, func(m *ServiceMetrics) {
+		m.HealthCheckCount++
+		m.LastHealthCheck = time.Now()
 		if err != nil {
-			metrics.HealthCheckErrors.Add(1)
+			m.HealthCheckErrors++
+		}
+	})
+}
+
+// snapshot 获取指标快照，运行中的服务的累计运行时长包含当前这一次运行
+func (r *serviceRecord) snapshot(now time.Time, options MetricsOptions) ServiceMetrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.metrics
+	running := m.State == StateRunning && !m.StartTime.IsZero() && now.After(m.StartTime)
+	if running {
+		m.TotalUptime += now.Sub(m.StartTime)
+	}
+
+	m.Latency = make(map[EventType]LatencyStats, len(r.latency))
+	for phase, h := range r.latency {
+		m.Latency[phase] = latencyStats(h.snapshot(), options.Percentiles)
+	}
+
+	m.Availability = make(map[time.Duration]float64, len(options.AvailabilityWindows))
+	for _, window := range options.AvailabilityWindows {
+		from := now.Add(-window)
+		if from.Before(r.registered) {
+			from = r.registered
+		}
+		span := now.Sub(from)
+		if span <= 0 {
+			continue
 		}
+
+		var up time.Duration
+		for _, tr := range r.uptime {
+			up += overlap(tr.from, tr.to, from, now)
+		}
+		if running {
+			up += overlap(m.StartTime, now, from, now)
+		}
+		m.Availability[window] = float64(up) / float64(span)
 	}
+	return m
 }
 
-// GetMetrics 获取服务指标
-func (mc *MetricsCollector) GetMetrics(serviceName string) (*ServiceMetrics, bool) {
-	mc.mu.RLock()
-	defer mc.mu.RUnlock()
+// latencySnapshots 获取各生命周期步骤的耗时直方图快照
+func (r *serviceRecord) latencySnapshots() map[EventType]histogramSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	metrics, exists := mc.metrics[serviceName]
+	result := make(map[EventType]histogramSnapshot, len(r.latency))
+	for phase, h := range r.latency {
+		result[phase] = h.snapshot()
+	}
+	return result
+}
+
+// overlap 返回区间 [a1, a2) 与 [b1, b2) 重叠的时长
+func overlap(a1, a2, b1, b2 time.Time) time.Duration {
+	if a1.Before(b1) {
+		a1 = b1
+	}
+	if a2.After(b2) {
+		a2 = b2
+	}
+	if !a2.After(a1) {
+		return 0
+	}
+	return a2.Sub(a1)
+}
+
+// latencyStats 将直方图快照转换为耗时统计
+func latencyStats(h histogramSnapshot, percentiles []float64) LatencyStats {
+	seconds := func(v float64) time.Duration {
+		return time.Duration(v * float64(time.Second))
+	}
+
+	stats := LatencyStats{
+		Count:       h.Count,
+		Sum:         seconds(h.Sum),
+		Min:         seconds(h.Min),
+		Max:         seconds(h.Max),
+		Buckets:     h.Buckets,
+		Counts:      h.Counts,
+		Percentiles: make(map[float64]time.Duration, len(percentiles)),
+	}
+	if h.Count > 0 {
+		stats.Mean = seconds(h.Sum / float64(h.Count))
+	}
+	for _, q := range percentiles {
+		stats.Percentiles[q] = seconds(h.quantile(q))
+	}
+	return stats
+}
+
+// GetMetrics 获取服务指标快照
+func (mc *MetricsCollector) GetMetrics(serviceName string) (*ServiceMetrics, bool) {
+	r, exists := mc.record(serviceName)
 	if !exists {
 		return nil, false
 	}
 
-	// 返回指标的副本
-	metricsCopy := *metrics
-	return &metricsCopy, true
+	m := r.snapshot(time.Now(), mc.options)
+	return &m, true
 }
 
-// GetAllMetrics 获取所有服务的指标
+// GetAllMetrics 获取所有服务的指标快照
 func (mc *MetricsCollector) GetAllMetrics() map[string]ServiceMetrics {
 	mc.mu.RLock()
 	defer mc.mu.RUnlock()
 
-	result := make(map[string]ServiceMetrics)
-	for name, metrics := range mc.metrics {
-		result[name] = *metrics
+	now := time.Now()
+	result := make(map[string]ServiceMetrics, len(mc.services))
+	for name, r := range mc.services {
+		result[name] = r.snapshot(now, mc.options)
 	}
 	return result
 }
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// readinessPollInterval 等待依赖就绪时的轮询间隔
+const readinessPollInterval = 50 * time.Millisecond
+
+// ReadinessChecker 可选接口，就绪探针：服务是否可以对外提供服务
+// 未实现时，服务处于 Running 状态且 HealthCheck 通过即视为就绪
+type ReadinessChecker interface {
+	ReadinessCheck(ctx context.Context) error
+}
+
+// LivenessChecker 可选接口，存活探针：服务是否需要被重启
+// 未实现时，Error 状态视为不存活，Running 状态以 HealthCheck 结果为准，其他状态视为存活
+type LivenessChecker interface {
+	LivenessCheck(ctx context.Context) error
+}
+
+// ProbeStatus 单个服务的探针结果
+type ProbeStatus struct {
+	Ready          bool
+	Live           bool
+	ReadinessError error
+	LivenessError  error
+	LastProbe      time.Time
+}
+
+// GroupProbeStatus 服务组的聚合探针结果，可直接映射到 Kubernetes 的 readiness/liveness 探针
+type GroupProbeStatus struct {
+	Ready    bool // 所有服务都已就绪
+	Live     bool // 所有服务都存活
+	Services map[string]ProbeStatus
+}
+
+// probeStore 保存最近一次探针结果
+type probeStore struct {
+	mu     sync.RWMutex
+	status map[string]ProbeStatus
+}
+
+// newProbeStore 创建探针结果存储
+func newProbeStore() *probeStore {
+	return &probeStore{
+		status: make(map[string]ProbeStatus),
+	}
+}
+
+func (ps *probeStore) set(name string, status ProbeStatus) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.status[name] = status
+}
+
+func (ps *probeStore) get(name string) (ProbeStatus, bool) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	status, ok := ps.status[name]
+	return status, ok
+}
+
+func (ps *probeStore) delete(name string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.status, name)
+}
+
+// probe 执行服务的健康检查、就绪与存活探针并保存结果
+func (sg *ServiceGroup) probe(ctx context.Context, s Service) ProbeStatus {
+	name := s.Name()
+	state := s.State()
+
+	began := time.Now()
+	healthErr := s.HealthCheck(ctx)
+	elapsed := time.Since(began)
+	sg.metrics.RecordHealthCheck(name, healthErr)
+	sg.metrics.RecordLatency(name, EventHealthCheck, elapsed)
+
+	status := ProbeStatus{LastProbe: time.Now()}
+
+	// 存活探针
+	if lc, ok := s.(LivenessChecker); ok {
+		status.LivenessError = lc.LivenessCheck(ctx)
+	} else {
+		switch state {
+		case StateError:
+			status.LivenessError = &ServiceError{
+				Code:    ErrInvalidState,
+				Message: fmt.Sprintf("service %s is in error state", name),
+			}
+		case StateRunning:
+			status.LivenessError = healthErr
+		}
+	}
+
+	// 就绪探针
+	if state != StateRunning {
+		status.ReadinessError = notRunningError(name, state)
+	} else if rc, ok := s.(ReadinessChecker); ok {
+		status.ReadinessError = rc.ReadinessCheck(ctx)
+	} else {
+		status.ReadinessError = healthErr
+	}
+
+	status.Ready = status.ReadinessError == nil
+	status.Live = status.LivenessError == nil
+	sg.probes.set(name, status)
+
+	sg.publishServiceEvent(s, EventHealthCheck, healthErr, map[string]interface{}{
+		MetadataDuration: elapsed,
+		MetadataReady:    status.Ready,
+		MetadataLive:     status.Live,
+	})
+	return status
+}
+
+// checkReady 只执行服务的就绪探针，不记录指标、不保存结果也不发布事件，用于启动时等待依赖就绪
+func checkReady(ctx context.Context, s Service) error {
+	if state := s.State(); state != StateRunning {
+		return notRunningError(s.Name(), state)
+	}
+	if rc, ok := s.(ReadinessChecker); ok {
+		return rc.ReadinessCheck(ctx)
+	}
+	return s.HealthCheck(ctx)
+}
+
+// notRunningError 服务未处于 Running 状态的错误
+func notRunningError(name string, state ServiceState) error {
+	return &ServiceError{
+		Code:    ErrInvalidState,
+		Message: fmt.Sprintf("service %s is not running (state: %s)", name, state),
+	}
+}
+
+// waitReady 等待依赖服务就绪
+func (sg *ServiceGroup) waitReady(ctx context.Context, name string) error {
+	svc, err := sg.GetService(name)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if state := svc.State(); state != StateRunning {
+			return notRunningError(name, state)
+		}
+		readyErr := checkReady(ctx, svc)
+		if readyErr == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &ServiceError{
+				Code:    ErrStartupTimeout,
+				Message: fmt.Sprintf("timeout waiting for service %s to become ready", name),
+				Err:     errors.Join(ctx.Err(), readyErr),
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckReadiness 立即执行所有服务的就绪探针，返回聚合的错误
+func (sg *ServiceGroup) CheckReadiness(ctx context.Context) error {
+	var errs []error
+	for _, s := range sg.probeAll(ctx) {
+		if s.ReadinessError != nil {
+			errs = append(errs, s.ReadinessError)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckLiveness 立即执行所有服务的存活探针，返回聚合的错误
+func (sg *ServiceGroup) CheckLiveness(ctx context.Context) error {
+	var errs []error
+	for _, s := range sg.probeAll(ctx) {
+		if s.LivenessError != nil {
+			errs = append(errs, s.LivenessError)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Probe 立即对所有服务执行一次探针，返回聚合结果
+// 需要同时判断就绪与存活时应使用它，而不是分别调用 CheckReadiness 和 CheckLiveness
+func (sg *ServiceGroup) Probe(ctx context.Context) GroupProbeStatus {
+	return aggregateProbes(sg.probeAll(ctx))
+}
+
+// probeAll 对所有服务执行探针
+func (sg *ServiceGroup) probeAll(ctx context.Context) map[string]ProbeStatus {
+	result := make(map[string]ProbeStatus)
+	sg.services.Range(func(key, value interface{}) bool {
+		result[key.(string)] = sg.probe(ctx, value.(Service))
+		return true
+	})
+	return result
+}
+
+// GetProbeStatus 获取服务最近一次的探针结果
+func (sg *ServiceGroup) GetProbeStatus(name string) (ProbeStatus, error) {
+	if _, err := sg.GetService(name); err != nil {
+		return ProbeStatus{}, err
+	}
+	status, ok := sg.probes.get(name)
+	if !ok {
+		return ProbeStatus{}, &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("service %s has not been probed yet", name),
+		}
+	}
+	return status, nil
+}
+
+// GetGroupProbeStatus 获取服务组最近一次的聚合探针结果
+// 尚未执行过探针的服务视为未就绪但存活
+func (sg *ServiceGroup) GetGroupProbeStatus() GroupProbeStatus {
+	services := make(map[string]ProbeStatus)
+	sg.services.Range(func(key, _ interface{}) bool {
+		name := key.(string)
+		status, ok := sg.probes.get(name)
+		if !ok {
+			status = ProbeStatus{Live: true}
+		}
+		services[name] = status
+		return true
+	})
+	return aggregateProbes(services)
+}
+
+// aggregateProbes 聚合各服务的探针结果
+func aggregateProbes(services map[string]ProbeStatus) GroupProbeStatus {
+	result := GroupProbeStatus{
+		Ready:    true,
+		Live:     true,
+		Services: services,
+	}
+	for _, status := range services {
+		result.Ready = result.Ready && status.Ready
+		result.Live = result.Live && status.Live
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusContentType Prometheus 文本格式的 Content-Type
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// allStates 所有服务状态，用于输出状态枚举指标
+var allStates = []ServiceState{
+	StateUninitialized,
+	StateInitialized,
+	StateStarting,
+	StateRunning,
+	StateStopping,
+	StateStopped,
+	StateError,
+}
+
+// PrometheusHandler 返回以 Prometheus 文本格式暴露服务组指标的 http.Handler
+func (sg *ServiceGroup) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := sg.WritePrometheus(w); err != nil {
+			sg.log.Error("Failed to write prometheus metrics",
+				"error", err)
+		}
+	})
+}
+
+// WritePrometheus 以 Prometheus 文本格式写出服务组内所有服务的指标
+func (sg *ServiceGroup) WritePrometheus(w io.Writer) error {
+	states := sg.GetServiceStates()
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	samples := sg.metrics.prometheusSamples(names)
+
+	bw := bufio.NewWriter(w)
+	pw := &promWriter{w: bw}
+
+	pw.header("service_state", "gauge", "Current lifecycle state of the service (1 for the active state).")
+	for _, name := range names {
+		for _, state := range allStates {
+			value := 0.0
+			if states[name] == state {
+				value = 1
+			}
+			pw.sample("service_state", value, "service", name, "state", state.String())
+		}
+	}
+
+	pw.header("service_restarts_total", "counter", "Total number of service restarts.")
+	for _, s := range samples {
+		pw.sample("service_restarts_total", float64(s.metrics.RestartCount), "service", s.name)
+	}
+
+	pw.header("service_starts_total", "counter", "Total number of times the service entered the Running state.")
+	for _, s := range samples {
+		pw.sample("service_starts_total", float64(s.metrics.StartCount), "service", s.name)
+	}
+
+	pw.header("service_errors_total", "counter", "Total number of failed lifecycle steps.")
+	for _, s := range samples {
+		pw.sample("service_errors_total", float64(s.metrics.ErrorCount), "service", s.name)
+	}
+
+	pw.header("service_health_checks_total", "counter", "Total number of health checks performed.")
+	for _, s := range samples {
+		pw.sample("service_health_checks_total", float64(s.metrics.HealthCheckCount), "service", s.name)
+	}
+
+	pw.header("service_health_check_failures_total", "counter", "Total number of failed health checks.")
+	for _, s := range samples {
+		pw.sample("service_health_check_failures_total", float64(s.metrics.HealthCheckErrors), "service", s.name)
+	}
+
+	pw.header("service_uptime_seconds", "gauge", "Accumulated running time of the service in seconds.")
+	for _, s := range samples {
+		pw.sample("service_uptime_seconds", s.metrics.TotalUptime.Seconds(), "service", s.name)
+	}
+
+	pw.header("service_last_error_timestamp_seconds", "gauge", "Unix timestamp of the last recorded error, 0 if none.")
+	for _, s := range samples {
+		value := 0.0
+		if !s.metrics.LastErrorTime.IsZero() {
+			value = float64(s.metrics.LastErrorTime.UnixNano()) / 1e9
+		}
+		pw.sample("service_last_error_timestamp_seconds", value, "service", s.name)
+	}
+
+	pw.header("service_availability_ratio", "gauge", "Share of wall time spent in the Running state over a rolling window.")
+	for _, s := range samples {
+		for _, window := range sg.metrics.options.AvailabilityWindows {
+			if ratio, ok := s.metrics.Availability[window]; ok {
+				pw.sample("service_availability_ratio", ratio, "service", s.name, "window", formatWindow(window))
+			}
+		}
+	}
+
+	pw.header("service_phase_duration_seconds", "histogram", "Duration of service lifecycle steps in seconds.")
+	for _, s := range samples {
+		phases := make([]string, 0, len(s.latency))
+		for phase := range s.latency {
+			phases = append(phases, string(phase))
+		}
+		sort.Strings(phases)
+		for _, phase := range phases {
+			pw.histogram("service_phase_duration_seconds", s.latency[EventType(phase)], "service", s.name, "phase", phase)
+		}
+	}
+
+	if pw.err != nil {
+		return pw.err
+	}
+	return bw.Flush()
+}
+
+// promSample 单个服务导出时的指标数据
+type promSample struct {
+	name    string
+	metrics ServiceMetrics
+	latency map[EventType]histogramSnapshot
+}
+
+// prometheusSamples 读取指定服务的指标数据
+func (mc *MetricsCollector) prometheusSamples(names []string) []promSample {
+	now := time.Now()
+	samples := make([]promSample, 0, len(names))
+	for _, name := range names {
+		r, exists := mc.record(name)
+		if !exists {
+			continue
+		}
+		samples = append(samples, promSample{
+			name:    name,
+			metrics: r.snapshot(now, mc.options),
+			latency: r.latencySnapshots(),
+		})
+	}
+	return samples
+}
+
+// promWriter 输出 Prometheus 文本格式
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, args...)
+}
+
+// header 输出 HELP 与 TYPE 行
+func (pw *promWriter) header(name, typ, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample 输出一个样本，labels 为键值对
+func (pw *promWriter) sample(name string, value float64, labels ...string) {
+	pw.printf("%s%s %s\n", name, formatLabels(labels), formatFloat(value))
+}
+
+// histogram 输出直方图的 bucket、sum 与 count
+func (pw *promWriter) histogram(name string, h histogramSnapshot, labels ...string) {
+	for i, upper := range h.Buckets {
+		pw.sample(name+"_bucket", float64(h.Counts[i]), append(labels, "le", formatFloat(upper))...)
+	}
+	pw.sample(name+"_bucket", float64(h.Count), append(labels, "le", "+Inf")...)
+	pw.sample(name+"_sum", h.Sum, labels...)
+	pw.sample(name+"_count", float64(h.Count), labels...)
+}
+
+// labelEscaper 转义标签值中的特殊字符
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels 格式化标签
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// formatFloat 格式化样本值
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatWindow 格式化可用率窗口，例如 5m、1h、90s
+func formatWindow(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return formatFloat(d.Seconds()) + "s"
+	}
+}
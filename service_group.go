@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +18,10 @@ type ServiceGroup struct {
 	ctx      context.Context
 	cancel   context.CancelFunc
 
+	// topoMu 保护服务集合与依赖图的结构性变更
+	topoMu    sync.Mutex
+	unobserve map[string]func() // 注销转发状态变更的观察者，受 topoMu 保护
+
 	// 配置选项
 	options ServiceGroupOptions
 
@@ -24,15 +31,29 @@ type ServiceGroup struct {
 	startupErr error
 	isStarting atomic.Bool
 
-	metrics *MetricsCollector
-	events  *EventManager
+	log        Logger
+	metrics    *MetricsCollector
+	events     *EventManager
+	supervisor *supervisor
+	probes     *probeStore
+	tracer     Tracer
+	spans      *spanIndex
+	bootReport atomic.Pointer[BootReport]
 }
 
 // ServiceGroupOptions 配置选项
 type ServiceGroupOptions struct {
+	Name                string // 服务组名称，非空时作为 group 属性附加到日志中
+	Logger              Logger // 服务组及其服务使用的日志器，nil 表示使用基于 slog.Default() 的日志器
 	StartTimeout        time.Duration
 	StopTimeout         time.Duration
+	ServiceStopTimeout  time.Duration // 单个服务的默认停止期限，0 表示使用 StopTimeout
 	HealthCheckInterval time.Duration
+	MaxConcurrency      int                 // 同一依赖层级内并行启动/停止的最大服务数，0 表示不限制
+	Supervisor          SupervisorOptions   // 自动重启配置
+	Events              EventManagerOptions // 事件投递配置
+	Metrics             MetricsOptions      // 耗时直方图与可用率配置
+	Tracer              Tracer              // 生命周期追踪，nil 表示不记录 span
 }
 
 // DefaultServiceGroupOptions 默认配置
@@ -40,6 +61,9 @@ var DefaultServiceGroupOptions = ServiceGroupOptions{
 	StartTimeout:        time.Minute,
 	StopTimeout:         time.Minute,
 	HealthCheckInterval: time.Second * 30,
+	Supervisor:          DefaultSupervisorOptions,
+	Events:              DefaultEventManagerOptions,
+	Metrics:             DefaultMetricsOptions,
 }
 
 // NewServiceGroup 创建新的服务组
@@ -55,18 +79,43 @@ func NewServiceGroup(ctx context.Context, opts ...ServiceGroupOptions) *ServiceG
 
 	ctx, cancel := context.WithCancel(ctx)
 	sg := &ServiceGroup{
-		depGraph: NewDependencyGraph(),
-		ctx:      ctx,
-		cancel:   cancel,
-		options:  options,
-		metrics:  NewMetricsCollector(),
-		events:   NewEventManager(),
+		depGraph:  NewDependencyGraph(),
+		ctx:       ctx,
+		cancel:    cancel,
+		unobserve: make(map[string]func()),
+		options:   options,
+		metrics:   NewMetricsCollector(options.Metrics),
+		events:    NewEventManager(options.Events),
+		probes:    newProbeStore(),
+		tracer:    options.Tracer,
+		spans:     newSpanIndex(),
+	}
+	if sg.tracer == nil {
+		sg.tracer = noopTracer{}
+	}
+	sg.supervisor = newSupervisor(sg, options.Supervisor)
+
+	sg.log = options.Logger
+	if sg.log == nil {
+		sg.log = defaultLogger()
 	}
+	if options.Name != "" {
+		sg.log = withAttrs(sg.log, "group", options.Name)
+	}
+	sg.events.logger = sg.log
 	return sg
 }
 
 // Add 添加服务到组
 func (sg *ServiceGroup) Add(s Service) error {
+	sg.topoMu.Lock()
+	defer sg.topoMu.Unlock()
+
+	return sg.add(s)
+}
+
+// add 注册服务，调用方需持有 topoMu
+func (sg *ServiceGroup) add(s Service) error {
 	if _, loaded := sg.services.LoadOrStore(s.Name(), s); loaded {
 		return &ServiceError{
 			Code:    ErrServiceAlreadyExists,
@@ -79,26 +128,62 @@ func (sg *ServiceGroup) Add(s Service) error {
 		Name:     s.Name(),
 		Priority: s.Priority(),
 		Deps:     s.Dependencies(),
+		Provides: providedType(s),
+		Injects:  injectionTargets(s),
+	}
+	if soft, ok := s.(SoftDependencies); ok {
+		node.Optional = soft.OptionalDependencies()
+		node.Weak = soft.WeakDependencies()
+		node.After = soft.AfterDependencies()
 	}
 
-	// 添加到依赖图
-	if err := sg.depGraph.AddNode(node); err != nil {
+	// 添加到依赖图，服务可以按任意顺序添加，缺失的必需依赖在 Start 时由 Validate 报告
+	if err := sg.depGraph.AddNode(node, AllowMissingDependencies()); err != nil {
 		sg.services.Delete(s.Name())
 		return err
 	}
 
 	// 注册服务指标
 	sg.metrics.RegisterService(s.Name())
+	sg.metrics.RecordStateChange(s.Name(), s.State(), time.Now())
+
+	// 将服务自身报告的状态变更转发到事件总线
+	if notifier, ok := s.(StateNotifier); ok {
+		sg.unobserve[s.Name()] = notifier.AddStateObserver(sg.onStateChange)
+	}
 
-	defaultLogger.Info("Added service to ServiceGroup",
-		"service", s.Name(),
+	// 未单独设置日志器的服务继承服务组的日志器
+	if inheritor, ok := s.(loggerInheritor); ok {
+		inheritor.inheritLogger(sg.log)
+	}
+
+	sg.serviceLogger(s).Debug("Added service to ServiceGroup",
 		"priority", s.Priority(),
 		"dependencies", s.Dependencies())
 	return nil
 }
 
-// Start 启动所有服务
+// Logger 返回服务组的日志器
+func (sg *ServiceGroup) Logger() Logger {
+	return sg.log
+}
+
+// serviceLogger 返回服务的子日志器，附加服务名和当前状态
+// 服务提供 GetLogger 时使用服务自己的日志器，使单独设置了日志器的服务也能收到服务组记录的生命周期日志
+func (sg *ServiceGroup) serviceLogger(s Service) Logger {
+	if ls, ok := s.(interface{ GetLogger() Logger }); ok {
+		return ls.GetLogger()
+	}
+	return newServiceLogger(sg.log, s)
+}
+
+// Start 启动所有服务，已在运行的服务（例如之前通过 StartOnly 启动的）会被跳过
 func (sg *ServiceGroup) Start() error {
+	return sg.start(sg.ctx)
+}
+
+// start 启动所有服务，parent 结束时中止启动，Run 借此在启动期间响应信号
+func (sg *ServiceGroup) start(parent context.Context) error {
 	if !sg.isStarting.CompareAndSwap(false, true) {
 		return &ServiceError{
 			Code:    ErrInvalidState,
@@ -106,24 +191,56 @@ func (sg *ServiceGroup) Start() error {
 		}
 	}
 
-	// 获取启动顺序
-	startOrder, err := sg.depGraph.GetStartOrder()
+	sg.startupWg.Add(1)
+	defer sg.startupWg.Done()
+
+	// 检查依赖完整性
+	if err := sg.depGraph.Validate(); err != nil {
+		sg.startupErr = err
+		sg.log.Error("Service group dependency validation failed",
+			"error", err)
+		return err
+	}
+
+	// 获取按层级分组的启动顺序
+	levels, err := sg.depGraph.GetStartLevels()
 	if err != nil {
+		sg.startupErr = err
 		return err
 	}
 
+	// 跳过已经运行的服务，例如之前通过 StartOnly 启动的部分服务
+	levels = sg.inactiveLevels(levels)
+
 	// 创建启动上下文
-	ctx, cancel := context.WithTimeout(sg.ctx, sg.options.StartTimeout)
+	ctx, cancel := context.WithTimeout(parent, sg.options.StartTimeout)
 	defer cancel()
 
-	// 按顺序启动服务
-	for _, name := range startOrder {
-		if err := sg.startService(ctx, name); err != nil {
-			return err
-		}
+	ctx, span := sg.tracer.Start(ctx, "ServiceGroup.Start", WithSpanAttributes(
+		"group", sg.options.Name,
+		"services", len(sg.ListServices()),
+		"levels", len(levels)))
+	defer span.End()
+
+	sg.log.Info("Starting service group",
+		"services", len(sg.ListServices()),
+		"levels", len(levels))
+
+	report, err := sg.startLevels(ctx, levels)
+	if err != nil {
+		sg.startupErr = err
+		span.RecordError(err)
+		sg.log.Error("Service group failed to start",
+			"duration", report.Duration,
+			"error", err)
+		return err
 	}
+	sg.log.Info("Service group started",
+		"duration", report.Duration,
+		"critical_chain", strings.Join(report.CriticalPath, " -> "))
 
-	// 启动健康检查（如果间隔大于0）
+	// 开始监督服务，启动健康检查（如果间隔大于0）
+	sg.supervisor.watch()
 	if sg.options.HealthCheckInterval > 0 {
 		go sg.healthCheckLoop()
 	}
@@ -131,6 +248,63 @@ func (sg *ServiceGroup) Start() error {
 	return nil
 }
 
+// startLevels 逐层启动服务，同一层内的服务并行启动，并记录启动报告
+// 某个服务启动失败时继续启动后续层级：依赖它的服务会因依赖未运行而失败，弱依赖它的服务仍会启动
+func (sg *ServiceGroup) startLevels(ctx context.Context, levels [][]string) (*BootReport, error) {
+	began := time.Now()
+	rec := newBootRecorder(began, levels, sg.depGraph)
+	ctx = contextWithBoot(ctx, rec)
+	start := func(ctx context.Context, name string) error {
+		err := sg.startWhenReady(ctx, name)
+		rec.finish(name, err)
+		return err
+	}
+
+	var startErrs []error
+	for _, level := range levels {
+		if err := sg.runLevel(ctx, level, start); err != nil {
+			startErrs = append(startErrs, err)
+		}
+	}
+	err := errors.Join(startErrs...)
+	report := rec.report(time.Since(began), err)
+	sg.bootReport.Store(report)
+	return report, err
+}
+
+// inactiveLevels 从各层级中去掉处于 Starting 或 Running 状态的服务，并去掉因此变空的层级
+func (sg *ServiceGroup) inactiveLevels(levels [][]string) [][]string {
+	pending := levels[:0]
+	for _, level := range levels {
+		var inactive []string
+		for _, name := range level {
+			if svc, err := sg.GetService(name); err == nil && !isActive(svc.State()) {
+				inactive = append(inactive, name)
+			}
+		}
+		if len(inactive) > 0 {
+			pending = append(pending, inactive)
+		}
+	}
+	return pending
+}
+
+// stopLevels 逆序逐层停止处于活动状态的服务，服务的 Stop 步骤链接到先于它停止的依赖方
+func (sg *ServiceGroup) stopLevels(ctx context.Context, levels [][]string) error {
+	dependents := sg.depGraph.dependents((*ServiceNode).orderDeps)
+	stop := func(ctx context.Context, name string) error {
+		return sg.stopIfActive(ctx, name, WithLinks(sg.spans.stopLinks(ctx, dependents[name])...))
+	}
+
+	var stopErrs []error
+	for i := len(levels) - 1; i >= 0; i-- {
+		if err := sg.runLevel(ctx, levels[i], stop); err != nil {
+			stopErrs = append(stopErrs, err)
+		}
+	}
+	return errors.Join(stopErrs...)
+}
+
 // Stop 停止所有服务
 func (sg *ServiceGroup) Stop() error {
 	sg.cancel() // 触发所有服务停止
@@ -139,32 +313,149 @@ func (sg *ServiceGroup) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), sg.options.StopTimeout)
 	defer cancel()
 
-	// 获取逆序的启动顺序作为停止顺序
-	stopOrder, err := sg.depGraph.GetStartOrder()
+	// 逆序逐层停止，保证服务总是在其依赖方之后停止
+	levels, err := sg.depGraph.GetStartLevels()
 	if err != nil {
 		return err
 	}
-	for i := len(stopOrder)/2 - 1; i >= 0; i-- {
-		opp := len(stopOrder) - 1 - i
-		stopOrder[i], stopOrder[opp] = stopOrder[opp], stopOrder[i]
+
+	ctx, span := sg.tracer.Start(ctx, "ServiceGroup.Stop", WithSpanAttributes(
+		"group", sg.options.Name,
+		"services", len(sg.ListServices()),
+		"levels", len(levels)))
+	defer span.End()
+
+	began := time.Now()
+	sg.log.Info("Stopping service group")
+
+	if err := sg.stopLevels(ctx, levels); err != nil {
+		span.RecordError(err)
+		sg.log.Error("Service group stopped with errors",
+			"duration", time.Since(began),
+			"error", err)
+		return err
+	}
+	sg.log.Info("Service group stopped",
+		"duration", time.Since(began))
+	return nil
+}
+
+// runLevel 并行地对同一层级的服务执行操作，并发数受 MaxConcurrency 限制
+func (sg *ServiceGroup) runLevel(ctx context.Context, names []string, fn func(context.Context, string) error) error {
+	limit := sg.options.MaxConcurrency
+	if limit <= 0 || limit > len(names) {
+		limit = len(names)
+	}
+
+	sem := make(chan struct{}, limit)
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(ctx, name); err != nil {
+				errs[i] = err
+				sg.log.Error("Service operation failed",
+					"service", name,
+					"error", err)
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// startWhenReady 确认依赖满足启动条件后启动服务
+func (sg *ServiceGroup) startWhenReady(ctx context.Context, name string) error {
+	node, ok := sg.depGraph.GetNode(name)
+	if !ok {
+		return &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("service %s not found", name),
+		}
 	}
 
-	// 按顺序停止服务
-	var stopErr error
-	for _, name := range stopOrder {
-		if err := sg.stopService(ctx, name); err != nil {
-			stopErr = err
-			defaultLogger.Error("Error stopping service",
-				"service", name,
-				"error", err)
+	// 依赖位于更早的层级，此时已经完成启动
+	// 等待依赖的过程记录为 Wait span，服务的各个 span 都链接到它等待过的依赖的 Start span
+	waited := append(append([]string(nil), node.Deps...), node.Optional...)
+	links := WithLinks(sg.spans.startLinks(ctx, waited)...)
+	if len(waited)+len(node.Weak) > 0 {
+		waitCtx, span := sg.tracer.Start(ctx, name+".Wait", links, WithSpanAttributes(
+			"service", name,
+			"phase", "Wait"))
+		began := time.Now()
+		err := sg.awaitDependencies(waitCtx, node)
+		bootFromContext(ctx).recordPhase(name, "Wait", began, time.Since(began))
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			return err
+		}
+	}
+	return sg.startService(ctx, name, links)
+}
+
+// awaitDependencies 等待服务的依赖满足启动条件
+// 必需依赖必须存在且已就绪，可选依赖存在时必须已就绪，弱依赖未就绪时仅记录警告
+func (sg *ServiceGroup) awaitDependencies(ctx context.Context, node *ServiceNode) error {
+	name := node.Name
+	check := func(dep string, kind DependencyKind) error {
+		svc, ok := sg.services.Load(dep)
+		if !ok {
+			if kind == DependencyRequired {
+				return &ServiceError{
+					Code:    ErrDependencyFailed,
+					Message: fmt.Sprintf("required dependency %s of service %s not found", dep, name),
+				}
+			}
+			return nil
+		}
+		if kind == DependencyWeak {
+			if err := checkReady(ctx, svc.(Service)); err != nil {
+				sg.log.Warn("Weak dependency is not ready",
+					"service", name,
+					"dependency", dep,
+					"error", err)
+			}
+			return nil
 		}
+		// 等待依赖通过就绪探针，而不仅仅是进入 Running 状态
+		if err := sg.waitReady(ctx, dep); err != nil {
+			return &ServiceError{
+				Code:    ErrDependencyFailed,
+				Message: fmt.Sprintf("%s dependency %s of service %s is not ready", kind, dep, name),
+				Err:     err,
+			}
+		}
+		return nil
 	}
 
-	return stopErr
+	for _, dep := range node.Deps {
+		if err := check(dep, DependencyRequired); err != nil {
+			return err
+		}
+	}
+	for _, dep := range node.Optional {
+		if err := check(dep, DependencyOptional); err != nil {
+			return err
+		}
+	}
+	for _, dep := range node.Weak {
+		if err := check(dep, DependencyWeak); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// startService 启动单个服务
-func (sg *ServiceGroup) startService(ctx context.Context, name string) error {
+// startService 启动单个服务，opts 应用于 Init 和 Start 步骤的 span
+func (sg *ServiceGroup) startService(ctx context.Context, name string, opts ...SpanOption) error {
 	service, ok := sg.services.Load(name)
 	if !ok {
 		return &ServiceError{
@@ -174,7 +465,30 @@ func (sg *ServiceGroup) startService(ctx context.Context, name string) error {
 	}
 
 	s := service.(Service)
-	if err := s.Start(ctx); err != nil {
+	sg.supervisor.setStopped(name, false)
+
+	// 注入已运行依赖导出的值
+	ctx, err := sg.inject(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	// 未初始化或处于 Error 状态的服务需要先（重新）初始化
+	if state := s.State(); state == StateUninitialized || state == StateError {
+		if _, err := sg.runPhase(ctx, s, EventInit, s.Init, opts...); err != nil {
+			return &ServiceError{
+				Code:    ErrStartupFailed,
+				Message: fmt.Sprintf("failed to initialize service %s", name),
+				Err:     err,
+			}
+		}
+	}
+
+	log := sg.serviceLogger(s)
+	log.Debug("Starting service")
+
+	elapsed, err := sg.runPhase(ctx, s, EventStart, s.Start, opts...)
+	if err != nil {
 		return &ServiceError{
 			Code:    ErrStartupFailed,
 			Message: fmt.Sprintf("failed to start service %s", name),
@@ -182,11 +496,13 @@ func (sg *ServiceGroup) startService(ctx context.Context, name string) error {
 		}
 	}
 
+	log.Info("Service started",
+		"duration", elapsed)
 	return nil
 }
 
-// stopService 停止单个服务
-func (sg *ServiceGroup) stopService(ctx context.Context, name string) error {
+// stopService 停止单个服务，opts 应用于 Stop 步骤的 span
+func (sg *ServiceGroup) stopService(ctx context.Context, name string, opts ...SpanOption) error {
 	svc, ok := sg.services.Load(name)
 	if !ok {
 		return &ServiceError{
@@ -196,19 +512,34 @@ func (sg *ServiceGroup) stopService(ctx context.Context, name string) error {
 	}
 
 	service := svc.(Service)
+	// 在状态变化之前标记，避免健康检查把主动停止当作意外退出
+	sg.supervisor.setStopped(name, true)
 
-	// 记录停止指标
-	sg.metrics.RecordStop(name)
+	log := sg.serviceLogger(service)
+	log.Debug("Stopping service")
 
-	if err := service.Stop(ctx); err != nil {
-		// 记录错误指标
-		sg.metrics.RecordError(name, err)
+	elapsed, err := sg.runPhase(ctx, service, EventStop, service.Stop, opts...)
+	if err != nil {
 		return fmt.Errorf("failed to stop service %s: %w", name, err)
 	}
 
+	log.Info("Service stopped",
+		"duration", elapsed)
 	return nil
 }
 
+// stopIfActive 停止处于 Starting 或 Running 状态的服务，其他状态的服务无需停止
+func (sg *ServiceGroup) stopIfActive(ctx context.Context, name string, opts ...SpanOption) error {
+	svc, err := sg.GetService(name)
+	if err != nil {
+		return err
+	}
+	if !isActive(svc.State()) {
+		return nil
+	}
+	return sg.stopService(ctx, name, opts...)
+}
+
 // healthCheckLoop 运行健康检查循环
 func (sg *ServiceGroup) healthCheckLoop() {
 	ticker := time.NewTicker(sg.options.HealthCheckInterval)
@@ -221,17 +552,27 @@ func (sg *ServiceGroup) healthCheckLoop() {
 		case <-ticker.C:
 			sg.services.Range(func(key, value interface{}) bool {
 				service := value.(Service)
-				if err := service.HealthCheck(sg.ctx); err != nil {
-					defaultLogger.Error("Service health check failed",
-						"service", service.Name(),
-						"error", err)
+				status := sg.probe(sg.ctx, service)
+				if !status.Live {
+					sg.serviceLogger(service).Error("Service liveness check failed",
+						"error", status.LivenessError)
+				} else if !status.Ready && service.State() == StateRunning {
+					sg.serviceLogger(service).Warn("Service readiness check failed",
+						"error", status.ReadinessError)
 				}
+				// 交给监督器根据存活探针决定是否需要重启
+				sg.supervisor.observe(service, status.LivenessError)
 				return true
 			})
 		}
 	}
 }
 
+// BootReport 返回最近一次 Start 或 StartOnly 的启动报告，尚未启动过或依赖校验失败时返回 nil
+func (sg *ServiceGroup) BootReport() *BootReport {
+	return sg.bootReport.Load()
+}
+
 // WaitForStart 等待所有服务启动完成
 func (sg *ServiceGroup) WaitForStart(ctx context.Context) error {
 	done := make(chan struct{})
@@ -252,8 +593,58 @@ func (sg *ServiceGroup) WaitForStart(ctx context.Context) error {
 	}
 }
 
+// StopReport 优雅停止报告
+type StopReport struct {
+	Stopped    []string                 // 正常停止的服务
+	Skipped    []string                 // 未在运行、无需停止的服务
+	TimedOut   []string                 // 超过停止期限的服务
+	NotStopped []string                 // 等待依赖方时超过总期限、从未调用 Stop 的服务
+	Failed     map[string]error         // 停止失败的服务
+	Durations  map[string]time.Duration // 每个服务从开始停止到结束的耗时
+}
+
+// stopOutcome 单个服务的停止结果
+type stopOutcome int
+
+const (
+	stopDone stopOutcome = iota
+	stopSkipped
+	stopTimedOut
+	stopFailed
+	stopAbandoned
+)
+
+// stopProgress GracefulStop 中各服务的停止进度
+type stopProgress struct {
+	done    map[string]chan struct{} // 服务的停止流程结束（无论结果）时关闭
+	mu      sync.Mutex
+	expired map[string]bool // 超过自身停止期限的服务
+}
+
+// expire 记录服务超过了自身的停止期限
+func (p *stopProgress) expire(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expired[name] = true
+}
+
+// isExpired 服务是否超过了自身的停止期限
+func (p *stopProgress) isExpired(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.expired[name]
+}
+
 // GracefulStop 优雅停止所有服务
 func (sg *ServiceGroup) GracefulStop(ctx context.Context) error {
+	_, err := sg.GracefulStopWithReport(ctx)
+	return err
+}
+
+// GracefulStopWithReport 按依赖关系优雅停止所有服务并返回停止报告
+// 每个服务都会等待其所有依赖方进入 Stopped 或 Error 状态（或超过依赖方自身的停止期限）后才开始停止，
+// 并且单个服务的停止时间受 StopTimeout 限制（可通过 StopTimeouter 接口覆盖）
+func (sg *ServiceGroup) GracefulStopWithReport(ctx context.Context) (*StopReport, error) {
 	// 先停止健康检查
 	sg.cancel()
 
@@ -261,58 +652,253 @@ func (sg *ServiceGroup) GracefulStop(ctx context.Context) error {
 	stopCtx, cancel := context.WithTimeout(ctx, sg.options.StopTimeout)
 	defer cancel()
 
-	// 获取停止顺序（依赖关系的反序）
-	stopOrder, err := sg.depGraph.GetStartOrder()
-	if err != nil {
-		return &ServiceError{
+	// 检查依赖关系是否有效
+	if _, err := sg.depGraph.GetStartLevels(); err != nil {
+		return nil, &ServiceError{
 			Code:    ErrShutdownFailed,
 			Message: "failed to determine service stop order",
 			Err:     err,
 		}
 	}
+	dependents := sg.depGraph.dependents((*ServiceNode).orderDeps)
+
+	names := sg.ListServices()
+	stopCtx, span := sg.tracer.Start(stopCtx, "ServiceGroup.GracefulStop", WithSpanAttributes(
+		"group", sg.options.Name,
+		"services", len(names)))
+	defer span.End()
+
+	progress := &stopProgress{
+		done:    make(map[string]chan struct{}, len(names)),
+		expired: make(map[string]bool),
+	}
+	for _, name := range names {
+		progress.done[name] = make(chan struct{})
+	}
 
-	// 反转顺序
-	for i := len(stopOrder)/2 - 1; i >= 0; i-- {
-		opp := len(stopOrder) - 1 - i
-		stopOrder[i], stopOrder[opp] = stopOrder[opp], stopOrder[i]
+	report := &StopReport{
+		Failed:    make(map[string]error),
+		Durations: make(map[string]time.Duration),
 	}
+	var mu sync.Mutex
 
 	// 跟踪停止进度
-	sg.shutdownWg.Add(len(stopOrder))
+	sg.shutdownWg.Add(len(names))
 
-	// 按顺序停止服务
-	for _, name := range stopOrder {
+	for _, name := range names {
 		go func(serviceName string) {
 			defer sg.shutdownWg.Done()
+			defer close(progress.done[serviceName])
+
+			outcome, elapsed, err := sg.stopAfterDependents(stopCtx, serviceName, dependents[serviceName], progress)
+			switch outcome {
+			case stopTimedOut:
+				progress.expire(serviceName)
+				if svc, lookupErr := sg.GetService(serviceName); lookupErr == nil {
+					sg.publishServiceEvent(svc, EventError, err, map[string]interface{}{
+						MetadataDuration: elapsed,
+						MetadataPhase:    EventStop,
+					})
+				}
+			case stopAbandoned:
+				sg.log.Error("Service was never stopped",
+					"service", serviceName,
+					"error", err)
+			}
 
-			if err := sg.stopService(stopCtx, serviceName); err != nil {
-				sg.events.PublishEvent(ServiceEvent{
-					ServiceName: serviceName,
-					EventType:   EventStop,
-					Error:       err,
-					Time:        time.Now(),
-				})
+			mu.Lock()
+			defer mu.Unlock()
+			report.Durations[serviceName] = elapsed
+			switch outcome {
+			case stopDone:
+				report.Stopped = append(report.Stopped, serviceName)
+			case stopSkipped:
+				report.Skipped = append(report.Skipped, serviceName)
+			case stopTimedOut:
+				report.TimedOut = append(report.TimedOut, serviceName)
+			case stopAbandoned:
+				report.NotStopped = append(report.NotStopped, serviceName)
+			case stopFailed:
+				report.Failed[serviceName] = err
 			}
 		}(name)
 	}
 
 	// 等待所有服务停止或超时
-	done := make(chan struct{})
+	finished := make(chan struct{})
 	go func() {
 		sg.shutdownWg.Wait()
-		close(done)
+		close(finished)
 	}()
 
 	select {
 	case <-ctx.Done():
-		return &ServiceError{
+		err := &ServiceError{
 			Code:    ErrShutdownTimeout,
 			Message: "timeout waiting for services to stop",
 			Err:     ctx.Err(),
 		}
-	case <-done:
-		return nil
+		span.RecordError(err)
+		return nil, err
+	case <-finished:
+	}
+
+	sort.Strings(report.Stopped)
+	sort.Strings(report.Skipped)
+	sort.Strings(report.TimedOut)
+	sort.Strings(report.NotStopped)
+
+	if len(report.TimedOut) > 0 || len(report.NotStopped) > 0 {
+		var problems []string
+		if len(report.TimedOut) > 0 {
+			problems = append(problems, "services timed out while stopping: "+strings.Join(report.TimedOut, ", "))
+		}
+		if len(report.NotStopped) > 0 {
+			problems = append(problems, "services never stopped: "+strings.Join(report.NotStopped, ", "))
+		}
+		err := &ServiceError{
+			Code:    ErrShutdownTimeout,
+			Message: strings.Join(problems, "; "),
+		}
+		span.RecordError(err)
+		return report, err
+	}
+	if len(report.Failed) > 0 {
+		errs := make([]error, 0, len(report.Failed))
+		for _, err := range report.Failed {
+			errs = append(errs, err)
+		}
+		err := &ServiceError{
+			Code:    ErrShutdownFailed,
+			Message: fmt.Sprintf("%d services failed to stop", len(report.Failed)),
+			Err:     errors.Join(errs...),
+		}
+		span.RecordError(err)
+		return report, err
+	}
+	return report, nil
+}
+
+// stopAfterDependents 等待所有依赖方停止或超过各自的停止期限后，在单个服务的期限内停止该服务
+func (sg *ServiceGroup) stopAfterDependents(ctx context.Context, name string, dependents []string, progress *stopProgress) (stopOutcome, time.Duration, error) {
+	// 等待依赖方进入 Stopped 或 Error 状态，等待过程记录为 Wait span
+	if len(dependents) > 0 {
+		waitCtx, span := sg.tracer.Start(ctx, name+".Wait", WithSpanAttributes(
+			"service", name,
+			"phase", "Wait"))
+		err := sg.awaitDependents(waitCtx, name, dependents, progress)
+		for _, link := range sg.spans.stopLinks(ctx, dependents) {
+			span.AddLink(link)
+		}
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			return stopAbandoned, 0, err
+		}
+	}
+
+	svc, err := sg.GetService(name)
+	if err != nil {
+		return stopFailed, 0, err
+	}
+	if !isActive(svc.State()) {
+		return stopSkipped, 0, nil
+	}
+
+	began := time.Now()
+	svcCtx, cancel := context.WithTimeout(ctx, sg.stopTimeoutFor(svc))
+	defer cancel()
+
+	// Stop 步骤链接到等待过的依赖方的 Stop span
+	links := WithLinks(sg.spans.stopLinks(ctx, dependents)...)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sg.stopService(svcCtx, name, links)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return stopFailed, time.Since(began), err
+		}
+		return stopDone, time.Since(began), nil
+	case <-svcCtx.Done():
+		return stopTimedOut, time.Since(began), &ServiceError{
+			Code:    ErrShutdownTimeout,
+			Message: fmt.Sprintf("timeout stopping service %s", name),
+			Err:     svcCtx.Err(),
+		}
+	}
+}
+
+// awaitDependents 等待服务的依赖方停止，只有 ctx 结束时返回错误
+// 超过自身停止期限的依赖方不再等待，避免一个卡住的服务让它的所有依赖都无法停止
+func (sg *ServiceGroup) awaitDependents(ctx context.Context, name string, dependents []string, progress *stopProgress) error {
+	for _, dep := range dependents {
+		ch, ok := progress.done[dep]
+		if !ok {
+			continue
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+		}
+
+		// 依赖方可能正由其他操作停止，最多等待它的停止期限
+		if ctx.Err() == nil && !progress.isExpired(dep) {
+			if svc, err := sg.GetService(dep); err == nil {
+				waitCtx, cancel := context.WithTimeout(ctx, sg.stopTimeoutFor(svc))
+				sg.waitStopped(waitCtx, dep)
+				cancel()
+			}
+		}
+		if ctx.Err() != nil {
+			return &ServiceError{
+				Code:    ErrShutdownTimeout,
+				Message: fmt.Sprintf("timeout waiting for dependent %s of service %s to stop", dep, name),
+				Err:     ctx.Err(),
+			}
+		}
+	}
+	return nil
+}
+
+// waitStopped 等待服务离开运行中的状态（Starting/Running/Stopping）
+func (sg *ServiceGroup) waitStopped(ctx context.Context, name string) bool {
+	svc, err := sg.GetService(name)
+	if err != nil {
+		return true
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		switch svc.State() {
+		case StateStarting, StateRunning, StateStopping:
+		default:
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopTimeoutFor 获取单个服务的停止期限
+func (sg *ServiceGroup) stopTimeoutFor(s Service) time.Duration {
+	if t, ok := s.(StopTimeouter); ok {
+		if d := t.StopTimeout(); d > 0 {
+			return d
+		}
+	}
+	if sg.options.ServiceStopTimeout > 0 {
+		return sg.options.ServiceStopTimeout
 	}
+	return sg.options.StopTimeout
 }
 
 // ServiceGroupState 服务组状态
@@ -359,7 +945,22 @@ func (sg *ServiceGroup) GetServiceMetrics(name string) (*ServiceMetrics, error)
 	return metrics, nil
 }
 
-// AddEventListener 添加事件监听器
-func (sg *ServiceGroup) AddEventListener(eventType EventType, listener EventListener) {
-	sg.events.AddListener(eventType, listener)
+// AddEventListener 添加事件监听器，返回可用于 Events().RemoveListenerByID 的标识
+func (sg *ServiceGroup) AddEventListener(eventType EventType, listener EventListener) ListenerID {
+	return sg.events.AddListener(eventType, listener)
+}
+
+// Subscribe 订阅服务组中满足过滤条件的事件，参见 EventManager.Subscribe
+func (sg *ServiceGroup) Subscribe(ctx context.Context, filter EventFilter, opts ...SubscribeOption) (<-chan ServiceEvent, func()) {
+	return sg.events.Subscribe(ctx, filter, opts...)
+}
+
+// EventHistory 返回服务组最近的事件中满足过滤条件的部分，参见 EventManager.History
+func (sg *ServiceGroup) EventHistory(filter EventFilter) []ServiceEvent {
+	return sg.events.History(filter)
+}
+
+// Events 返回服务组的事件管理器
+func (sg *ServiceGroup) Events() *EventManager {
+	return sg.events
 }
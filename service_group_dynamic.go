@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RemoveOption 移除服务的选项
+type RemoveOption func(*removeOptions)
+
+// removeOptions 移除服务的配置
+type removeOptions struct {
+	cascade bool
+}
+
+// WithCascade 级联停止并移除所有直接或间接依赖该服务的服务
+func WithCascade() RemoveOption {
+	return func(o *removeOptions) {
+		o.cascade = true
+	}
+}
+
+// AddAndStart 添加服务到组，如果服务组已经启动则立即启动该服务
+// 服务的依赖必须已经就绪，启动失败时服务会从组中移除
+func (sg *ServiceGroup) AddAndStart(ctx context.Context, s Service) error {
+	sg.topoMu.Lock()
+	defer sg.topoMu.Unlock()
+
+	if err := sg.add(s); err != nil {
+		return err
+	}
+	sg.publishServiceEvent(s, EventAdd, nil, nil)
+
+	if !sg.isRunning() {
+		return nil
+	}
+
+	name := s.Name()
+	for _, dep := range s.Dependencies() {
+		if _, ok := sg.services.Load(dep); !ok {
+			err := &ServiceError{
+				Code:    ErrDependencyFailed,
+				Message: fmt.Sprintf("dependency %s of service %s not found", dep, name),
+			}
+			sg.remove(name)
+			sg.publishServiceEvent(s, EventRemove, err, nil)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sg.options.StartTimeout)
+	defer cancel()
+
+	if err := sg.startWhenReady(ctx, name); err != nil {
+		sg.remove(name)
+		sg.publishServiceEvent(s, EventRemove, err, nil)
+		return err
+	}
+	return nil
+}
+
+// Remove 停止并从组中移除服务
+// 如果仍有运行中的服务依赖它，除非指定 WithCascade，否则拒绝移除；
+// 指定 WithCascade 时会按依赖顺序先停止并移除所有依赖方
+func (sg *ServiceGroup) Remove(ctx context.Context, name string, opts ...RemoveOption) error {
+	var o removeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sg.topoMu.Lock()
+	defer sg.topoMu.Unlock()
+
+	if _, err := sg.GetService(name); err != nil {
+		return err
+	}
+
+	dependents := sg.depGraph.TransitiveDependents(name, DependencyRequired, DependencyOptional)
+	targets := map[string]bool{name: true}
+	if o.cascade {
+		for _, d := range dependents {
+			targets[d] = true
+		}
+	} else {
+		var running []string
+		for _, d := range dependents {
+			if svc, err := sg.GetService(d); err == nil && isActive(svc.State()) {
+				running = append(running, d)
+			}
+		}
+		if len(running) > 0 {
+			return &ServiceError{
+				Code:    ErrDependencyFailed,
+				Message: fmt.Sprintf("service %s still has running dependents: %s", name, strings.Join(running, ", ")),
+			}
+		}
+	}
+
+	levels, err := sg.depGraph.GetStartLevels()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sg.options.StopTimeout)
+	defer cancel()
+
+	// 按依赖的逆序停止并移除
+	var stopErrs []error
+	for i := len(levels) - 1; i >= 0; i-- {
+		var active []string
+		for _, n := range levels[i] {
+			if !targets[n] {
+				continue
+			}
+			if svc, err := sg.GetService(n); err == nil && isActive(svc.State()) {
+				active = append(active, n)
+			}
+		}
+
+		var mu sync.Mutex
+		failed := make(map[string]bool)
+		var stopErr error
+		if len(active) > 0 {
+			stop := func(ctx context.Context, n string) error {
+				err := sg.stopService(ctx, n)
+				if err != nil {
+					mu.Lock()
+					failed[n] = true
+					mu.Unlock()
+				}
+				return err
+			}
+			stopErr = sg.runLevel(ctx, active, stop)
+		}
+
+		// 停止失败且仍处于活动状态的服务保留在组中，以便调用方重试
+		var kept []string
+		for _, n := range levels[i] {
+			if !targets[n] {
+				continue
+			}
+			svc, err := sg.GetService(n)
+			if err != nil {
+				continue
+			}
+			if failed[n] && isActive(svc.State()) {
+				kept = append(kept, n)
+				continue
+			}
+			sg.remove(n)
+			sg.publishServiceEvent(svc, EventRemove, nil, nil)
+		}
+
+		// 依赖方未能停止时不再停止它的依赖
+		if len(kept) > 0 {
+			return &ServiceError{
+				Code:    ErrShutdownFailed,
+				Message: fmt.Sprintf("services failed to stop and were not removed: %s", strings.Join(kept, ", ")),
+				Err:     errors.Join(append(stopErrs, stopErr)...),
+			}
+		}
+		if stopErr != nil {
+			stopErrs = append(stopErrs, stopErr)
+		}
+	}
+	return errors.Join(stopErrs...)
+}
+
+// remove 从服务集合、依赖图和指标中移除服务，调用方需持有 topoMu
+func (sg *ServiceGroup) remove(name string) {
+	if unobserve := sg.unobserve[name]; unobserve != nil {
+		unobserve()
+	}
+	delete(sg.unobserve, name)
+	sg.services.Delete(name)
+	sg.depGraph.RemoveNode(name)
+	sg.metrics.UnregisterService(name)
+	sg.probes.delete(name)
+	sg.spans.delete(name)
+
+	sg.supervisor.mu.Lock()
+	delete(sg.supervisor.policies, name)
+	delete(sg.supervisor.backoff, name)
+	delete(sg.supervisor.stopped, name)
+	sg.supervisor.mu.Unlock()
+
+	sg.log.Info("Removed service from ServiceGroup",
+		"service", name)
+}
+
+// isRunning 服务组是否已启动且尚未停止
+func (sg *ServiceGroup) isRunning() bool {
+	return sg.isStarting.Load() && sg.ctx.Err() == nil
+}
+
+// isActive 服务是否处于需要停止的状态
+func isActive(state ServiceState) bool {
+	return state == StateStarting || state == StateRunning
+}
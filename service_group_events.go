@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// publishServiceEvent 发布服务事件，事件中携带服务的当前状态
+func (sg *ServiceGroup) publishServiceEvent(s Service, eventType EventType, err error, metadata map[string]interface{}) {
+	sg.events.PublishEvent(ServiceEvent{
+		ServiceName: s.Name(),
+		EventType:   eventType,
+		State:       s.State(),
+		Time:        time.Now(),
+		Error:       err,
+		Metadata:    metadata,
+	})
+}
+
+// runPhase 执行一个生命周期步骤，记录耗时并发布对应事件
+// 步骤失败时额外发布 Error 事件；未实现 StateNotifier 的服务由服务组根据前后状态补发 StateChange 事件
+// 每个步骤都会创建一个 span，fn 收到的上下文携带该 span
+func (sg *ServiceGroup) runPhase(ctx context.Context, s Service, eventType EventType, fn func(context.Context) error, opts ...SpanOption) (time.Duration, error) {
+	ctx, span := sg.tracer.Start(ctx, s.Name()+"."+string(eventType), append([]SpanOption{
+		WithSpanAttributes("service", s.Name(), "phase", string(eventType)),
+	}, opts...)...)
+	switch eventType {
+	case EventStart:
+		sg.spans.setStart(s.Name(), span.SpanContext())
+	case EventStop:
+		sg.spans.setStop(s.Name(), span.SpanContext())
+	}
+
+	from := s.State()
+	began := time.Now()
+	err := fn(ctx)
+	elapsed := time.Since(began)
+	sg.metrics.RecordLatency(s.Name(), eventType, elapsed)
+	if eventType == EventInit || eventType == EventStart {
+		bootFromContext(ctx).recordPhase(s.Name(), string(eventType), began, elapsed)
+	}
+
+	span.SetAttribute("state", s.State().String())
+	span.RecordError(err)
+	span.End()
+
+	if _, ok := s.(StateNotifier); !ok {
+		if to := s.State(); to != from {
+			sg.onStateChange(StateChange{
+				Service: s.Name(),
+				From:    from,
+				To:      to,
+				Time:    time.Now(),
+			})
+		}
+	}
+
+	sg.publishServiceEvent(s, eventType, err, map[string]interface{}{
+		MetadataDuration: elapsed,
+	})
+	if err != nil {
+		sg.metrics.RecordError(s.Name(), err)
+		sg.publishServiceEvent(s, EventError, err, map[string]interface{}{
+			MetadataDuration: elapsed,
+			MetadataPhase:    eventType,
+		})
+	}
+	return elapsed, err
+}
+
+// onStateChange 记录服务的状态变更指标并转发到事件总线
+func (sg *ServiceGroup) onStateChange(change StateChange) {
+	// 服务已被移除时不再转发
+	svc, ok := sg.services.Load(change.Service)
+	if !ok {
+		return
+	}
+
+	sg.metrics.RecordStateChange(change.Service, change.To, change.Time)
+
+	sg.events.PublishEvent(ServiceEvent{
+		ServiceName: change.Service,
+		EventType:   EventStateChange,
+		State:       change.To,
+		Time:        change.Time,
+		Metadata: map[string]interface{}{
+			MetadataFrom:     change.From,
+			MetadataTo:       change.To,
+			MetadataDuration: change.Duration,
+		},
+	})
+
+	// 服务进入 Error 状态或停止时立即交给监督器，不必等到下一次健康检查
+	if change.To == StateError || change.To == StateStopped {
+		sg.supervisor.observe(svc.(Service), nil)
+	}
+}
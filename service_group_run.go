@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// 建议的进程退出码
+const (
+	ExitCodeOK      = 0 // 正常退出
+	ExitCodeFailure = 1 // 启动失败、停止出错或服务组被监督器停止
+)
+
+// ConfigLoader 重新加载配置，返回以服务名为键的配置
+type ConfigLoader func(ctx context.Context) (map[string]interface{}, error)
+
+// RunOption Run 的配置选项
+type RunOption func(*runOptions)
+
+// runOptions Run 的配置
+type runOptions struct {
+	signals <-chan os.Signal
+	reload  ConfigLoader
+}
+
+// WithSignals 使用指定的信号来源代替 os/signal，便于在测试中注入信号
+func WithSignals(signals <-chan os.Signal) RunOption {
+	return func(o *runOptions) {
+		o.signals = signals
+	}
+}
+
+// WithReload 设置收到 SIGHUP 时重新加载配置的函数
+// 返回的配置会通过 Update 传递给对应名称的服务
+func WithReload(loader ConfigLoader) RunOption {
+	return func(o *runOptions) {
+		o.reload = loader
+	}
+}
+
+// Run 启动服务组并阻塞，直到 ctx 结束、收到 SIGINT/SIGTERM 或服务组被监督器停止
+//
+// 收到第一个 SIGINT/SIGTERM 时优雅停止所有服务，停止期间再次收到则强制退出；
+// 启动期间收到信号或 ctx 结束时会中止启动，转为优雅停止已启动的服务。
+// 收到 SIGHUP 时重新加载配置并调用所有服务的 Update。
+// 返回前会关闭事件管理器，确保所有已发布的事件都已投递给监听器。
+// 返回所有错误的组合以及建议的进程退出码。
+func (sg *ServiceGroup) Run(ctx context.Context, opts ...RunOption) (int, error) {
+	var o runOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// 返回前投递完所有待处理的事件
+	defer sg.events.Close()
+
+	if o.signals == nil {
+		ch := make(chan os.Signal, 2)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(ch)
+		o.signals = ch
+	}
+
+	// 在后台启动，启动期间同样响应信号和 ctx
+	startCtx, abort := context.WithCancel(sg.ctx)
+	defer abort()
+	starting := make(chan error, 1)
+	go func() {
+		starting <- sg.start(startCtx)
+	}()
+
+	// 等待停止条件，启动完成后 starting 置为 nil
+wait:
+	for {
+		select {
+		case err := <-starting:
+			if err != nil {
+				stopErr := sg.Stop()
+				return ExitCodeFailure, errors.Join(err, stopErr)
+			}
+			starting = nil
+		case <-ctx.Done():
+			sg.log.Info("Context done, stopping service group")
+			break wait
+		case <-sg.ctx.Done():
+			// 监督器升级时服务组已在停止，等待其完成
+			if err := sg.supervisor.escalation(); err != nil {
+				sg.supervisor.waitHalted()
+				return ExitCodeFailure, err
+			}
+			// 其他情况（例如服务组与 Run 共用同一个已取消的上下文）仍需优雅停止
+			sg.log.Info("Service group context done, stopping service group")
+			break wait
+		case sig := <-o.signals:
+			if sig == syscall.SIGHUP {
+				sg.reload(ctx, o.reload)
+				continue
+			}
+			sg.log.Info("Received signal, stopping service group",
+				"signal", sig)
+			break wait
+		}
+	}
+
+	// 优雅停止，期间再次收到 SIGINT/SIGTERM 则强制退出
+	stopCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		// 先中止仍在进行的启动，等它返回后再停止已启动的服务
+		if starting != nil {
+			sg.log.Info("Aborting service group startup")
+			abort()
+			select {
+			case <-starting:
+			case <-stopCtx.Done():
+			}
+		}
+		done <- sg.GracefulStop(stopCtx)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return ExitCodeFailure, err
+			}
+			return ExitCodeOK, nil
+		case sig := <-o.signals:
+			if sig == syscall.SIGHUP {
+				continue
+			}
+			sg.log.Warn("Received second signal, forcing shutdown",
+				"signal", sig)
+			cancel()
+			err := errors.Join(&ServiceError{
+				Code:    ErrShutdownTimeout,
+				Message: fmt.Sprintf("forced shutdown by signal %s", sig),
+			}, <-done)
+			return forcedExitCode(sig), err
+		}
+	}
+}
+
+// reload 重新加载配置并更新服务
+func (sg *ServiceGroup) reload(ctx context.Context, loader ConfigLoader) {
+	if loader == nil {
+		sg.log.Warn("Received SIGHUP but no config loader is configured")
+		return
+	}
+
+	configs, err := loader(ctx)
+	if err != nil {
+		sg.log.Error("Failed to reload config",
+			"error", err)
+		return
+	}
+
+	for name, config := range configs {
+		if err := sg.UpdateService(ctx, name, config); err != nil {
+			sg.log.Error("Failed to update service config",
+				"service", name,
+				"error", err)
+		}
+	}
+}
+
+// forcedExitCode 强制退出时的退出码，遵循 shell 的 128+信号值 约定
+func forcedExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return ExitCodeFailure
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runResult Run 的返回值
+type runResult struct {
+	code int
+	err  error
+}
+
+// runGroup 在后台运行服务组，信号通过返回的通道注入
+func runGroup(t *testing.T, ctx context.Context, sg *ServiceGroup, opts ...RunOption) (chan<- os.Signal, <-chan runResult) {
+	t.Helper()
+	signals := make(chan os.Signal)
+	result := make(chan runResult, 1)
+	go func() {
+		code, err := sg.Run(ctx, append(opts, WithSignals(signals))...)
+		result <- runResult{code, err}
+	}()
+	return signals, result
+}
+
+// waitRunResult 等待 Run 返回
+func waitRunResult(t *testing.T, result <-chan runResult) runResult {
+	t.Helper()
+	select {
+	case r := <-result:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+		return runResult{}
+	}
+}
+
+// waitState 等待服务进入指定状态
+func waitState(t *testing.T, s Service, state ServiceState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is %s, want %s", s.Name(), s.State(), state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunStopsOnSignal(t *testing.T) {
+	sg := newTestGroup(t, ServiceGroupOptions{})
+	db := NewBaseService("db", nil)
+	api := NewBaseService("api", []string{"db"})
+	sg.Add(db)
+	sg.Add(api)
+
+	signals, result := runGroup(t, context.Background(), sg)
+	waitState(t, api, StateRunning)
+	signals <- syscall.SIGTERM
+
+	r := waitRunResult(t, result)
+	if r.code != ExitCodeOK || r.err != nil {
+		t.Fatalf("Run = %d, %v, want %d, nil", r.code, r.err, ExitCodeOK)
+	}
+	for _, s := range []Service{db, api} {
+		if s.State() != StateStopped {
+			t.Errorf("%s is %s after Run returned", s.Name(), s.State())
+		}
+	}
+}
+
+func TestRunAbortsStartupOnSignal(t *testing.T) {
+	sg := newTestGroup(t, ServiceGroupOptions{})
+	db := NewBaseService("db", nil)
+	starting := make(chan struct{})
+	slow := NewBaseService("slow", []string{"db"})
+	slow.SetStartFunc(func(ctx context.Context) error {
+		close(starting)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			return nil
+		}
+	})
+	next := NewBaseService("next", []string{"slow"})
+	sg.Add(db)
+	sg.Add(slow)
+	sg.Add(next)
+
+	began := time.Now()
+	signals, result := runGroup(t, context.Background(), sg)
+	<-starting
+	signals <- syscall.SIGINT
+
+	r := waitRunResult(t, result)
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Fatalf("Run took %s, startup was not aborted", elapsed)
+	}
+	if r.code != ExitCodeOK || r.err != nil {
+		t.Fatalf("Run = %d, %v, want %d, nil", r.code, r.err, ExitCodeOK)
+	}
+	for _, s := range []Service{db, slow, next} {
+		if isActive(s.State()) {
+			t.Errorf("%s is still %s after Run returned", s.Name(), s.State())
+		}
+	}
+}
+
+func TestRunForcesStopOnSecondSignal(t *testing.T) {
+	sg := newTestGroup(t, ServiceGroupOptions{})
+	stuck := NewBaseService("stuck", nil)
+	stopping := make(chan struct{})
+	stuck.SetStopFunc(func(ctx context.Context) error {
+		close(stopping)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	sg.Add(stuck)
+
+	signals, result := runGroup(t, context.Background(), sg)
+	waitState(t, stuck, StateRunning)
+	signals <- syscall.SIGINT
+	<-stopping
+	signals <- syscall.SIGINT
+
+	r := waitRunResult(t, result)
+	if want := 128 + int(syscall.SIGINT); r.code != want {
+		t.Fatalf("exit code = %d, want %d", r.code, want)
+	}
+	var se *ServiceError
+	if !errors.As(r.err, &se) || se.Code != ErrShutdownTimeout {
+		t.Fatalf("error = %v, want ErrShutdownTimeout", r.err)
+	}
+}
+
+func TestRunReloadsOnSIGHUP(t *testing.T) {
+	sg := newTestGroup(t, ServiceGroupOptions{})
+	api := NewBaseService("api", nil)
+	var mu sync.Mutex
+	var updates []interface{}
+	api.SetUpdateFunc(func(_ context.Context, config interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, config)
+		return nil
+	})
+	sg.Add(api)
+
+	reload := func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"api": "v2"}, nil
+	}
+	signals, result := runGroup(t, context.Background(), sg, WithReload(reload))
+	waitState(t, api, StateRunning)
+	signals <- syscall.SIGHUP
+	signals <- syscall.SIGTERM
+
+	if r := waitRunResult(t, result); r.code != ExitCodeOK || r.err != nil {
+		t.Fatalf("Run = %d, %v, want %d, nil", r.code, r.err, ExitCodeOK)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(updates) != 1 || updates[0] != "v2" {
+		t.Fatalf("updates = %v, want [v2]", updates)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	t.Run("context done", func(t *testing.T) {
+		sg := newTestGroup(t, ServiceGroupOptions{})
+		api := NewBaseService("api", nil)
+		sg.Add(api)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, result := runGroup(t, ctx, sg)
+		waitState(t, api, StateRunning)
+		cancel()
+
+		if r := waitRunResult(t, result); r.code != ExitCodeOK || r.err != nil {
+			t.Fatalf("Run = %d, %v, want %d, nil", r.code, r.err, ExitCodeOK)
+		}
+	})
+
+	t.Run("startup failure", func(t *testing.T) {
+		sg := newTestGroup(t, ServiceGroupOptions{})
+		api := NewBaseService("api", nil)
+		api.SetStartFunc(func(context.Context) error {
+			return errors.New("listen: address already in use")
+		})
+		sg.Add(api)
+
+		_, result := runGroup(t, context.Background(), sg)
+		if r := waitRunResult(t, result); r.code != ExitCodeFailure || r.err == nil {
+			t.Fatalf("Run = %d, %v, want %d and an error", r.code, r.err, ExitCodeFailure)
+		}
+	})
+
+	t.Run("stop failure", func(t *testing.T) {
+		sg := newTestGroup(t, ServiceGroupOptions{})
+		api := NewBaseService("api", nil)
+		api.SetStopFunc(func(context.Context) error {
+			return errors.New("flush failed")
+		})
+		sg.Add(api)
+
+		signals, result := runGroup(t, context.Background(), sg)
+		waitState(t, api, StateRunning)
+		signals <- syscall.SIGTERM
+
+		if r := waitRunResult(t, result); r.code != ExitCodeFailure || r.err == nil {
+			t.Fatalf("Run = %d, %v, want %d and an error", r.code, r.err, ExitCodeFailure)
+		}
+	})
+}
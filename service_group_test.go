@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestGroup 创建不输出日志的服务组
+func newTestGroup(t *testing.T, opts ServiceGroupOptions) *ServiceGroup {
+	t.Helper()
+	opts.Logger = NewLogger(slog.NewTextHandler(io.Discard, nil))
+	if opts.StartTimeout == 0 {
+		opts.StartTimeout = 5 * time.Second
+	}
+	if opts.StopTimeout == 0 {
+		opts.StopTimeout = 5 * time.Second
+	}
+	return NewServiceGroup(context.Background(), opts)
+}
+
+// newStressService 创建生命周期带有少量延迟的服务，拉长并发操作交错的窗口
+func newStressService(name string, deps []string, opts ...ServiceOption) *BaseService {
+	s := NewBaseService(name, deps, opts...)
+	pause := func(context.Context) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	s.SetInitFunc(pause)
+	s.SetStartFunc(pause)
+	s.SetStopFunc(pause)
+	return s
+}
+
+// 在启动和停止期间并发读取指标、导出 Prometheus 文本、执行探针、重启服务和订阅事件，配合 -race 运行
+func TestServiceGroupConcurrentStress(t *testing.T) {
+	for round := 0; round < 3; round++ {
+		t.Run(fmt.Sprintf("round%d", round), func(t *testing.T) {
+			sg := newTestGroup(t, ServiceGroupOptions{
+				HealthCheckInterval: 5 * time.Millisecond,
+				MaxConcurrency:      2,
+				Tracer:              NewMemoryTracer(),
+				Supervisor: SupervisorOptions{
+					Policy:         RestartOnFailure,
+					InitialBackoff: time.Millisecond,
+				},
+			})
+
+			services := []*BaseService{
+				newStressService("db", nil),
+				newStressService("cache", nil, WithPriority(PriorityHigh)),
+				newStressService("queue", nil, WithOptionalDependencies("cache")),
+				newStressService("auth", []string{"db"}, WithWeakDependencies("cache")),
+				newStressService("api", []string{"db", "auth"}, WithAfter("queue")),
+				newStressService("worker", []string{"queue"}),
+				newStressService("web", []string{"api"}),
+			}
+			names := make([]string, len(services))
+			for i, s := range services {
+				names[i] = s.Name()
+				if err := sg.Add(s); err != nil {
+					t.Fatalf("Add(%s): %v", s.Name(), err)
+				}
+			}
+
+			var delivered atomic.Int64
+			sg.AddEventListener(EventAll, EventListenerFunc(func(ServiceEvent) {
+				delivered.Add(1)
+			}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			var readers, restarters sync.WaitGroup
+			// 测试提前失败时也要等所有并发操作结束
+			defer func() {
+				cancel()
+				readers.Wait()
+				restarters.Wait()
+			}()
+			hammer := func(wg *sync.WaitGroup, fn func(i int)) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; ctx.Err() == nil; i++ {
+						fn(i)
+					}
+				}()
+			}
+
+			hammer(&readers, func(i int) {
+				name := names[i%len(names)]
+				if m, err := sg.GetServiceMetrics(name); err == nil {
+					_ = m.TotalUptime
+				}
+				_ = sg.GetGroupState()
+			})
+			hammer(&readers, func(int) {
+				if err := sg.WritePrometheus(io.Discard); err != nil {
+					t.Errorf("WritePrometheus: %v", err)
+				}
+			})
+			hammer(&readers, func(int) {
+				_ = sg.CheckReadiness(ctx)
+				_ = sg.CheckLiveness(ctx)
+				_ = sg.GetGroupProbeStatus()
+			})
+			hammer(&readers, func(i int) {
+				subCtx, subCancel := context.WithTimeout(ctx, 5*time.Millisecond)
+				events, unsubscribe := sg.Subscribe(subCtx, EventFilter{Services: []string{names[i%len(names)]}}, WithReplay(5))
+				for range events {
+				}
+				unsubscribe()
+				subCancel()
+			})
+			hammer(&readers, func(int) {
+				_ = sg.EventHistory(EventFilter{})
+				_ = sg.BootReport()
+				_ = sg.DependencyGraph().WriteJSON(io.Discard, WithServiceGroup(sg))
+			})
+
+			started := make(chan struct{})
+			restartCtx, stopRestarts := context.WithCancel(ctx)
+			restarters.Add(1)
+			go func() {
+				defer restarters.Done()
+				select {
+				case <-started:
+				case <-restartCtx.Done():
+					return
+				}
+				for i := 0; restartCtx.Err() == nil; i++ {
+					// 只重启没有依赖方的服务，避免与依赖方的启动相互影响
+					leaf := []string{"web", "worker"}[i%2]
+					_ = sg.RestartService(restartCtx, leaf)
+				}
+			}()
+
+			if err := sg.Start(); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+
+			stopRestarts()
+			restarters.Wait()
+
+			report, err := sg.GracefulStopWithReport(context.Background())
+			if err != nil {
+				t.Fatalf("GracefulStop: %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+			readers.Wait()
+			sg.Events().Close()
+
+			if len(report.TimedOut) > 0 || len(report.NotStopped) > 0 || len(report.Failed) > 0 {
+				t.Fatalf("unclean stop: %+v", report)
+			}
+			for _, s := range services {
+				if isActive(s.State()) {
+					t.Errorf("%s is still %s after GracefulStop", s.Name(), s.State())
+				}
+				m, err := sg.GetServiceMetrics(s.Name())
+				if err != nil {
+					t.Fatalf("GetServiceMetrics(%s): %v", s.Name(), err)
+				}
+				if m.StartCount == 0 {
+					t.Errorf("%s has no recorded starts", s.Name())
+				}
+			}
+			if delivered.Load() == 0 {
+				t.Error("listener received no events")
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // GetService 获取指定服务
@@ -22,21 +24,164 @@ func (sg *ServiceGroup) UpdateService(ctx context.Context, name string, config i
 	if err != nil {
 		return err
 	}
-	return svc.Update(ctx, config)
+	_, err = sg.runPhase(ctx, svc, EventUpdate, func(ctx context.Context) error {
+		return svc.Update(ctx, config)
+	})
+	return err
 }
 
 // RestartService 重启指定服务
 func (sg *ServiceGroup) RestartService(ctx context.Context, name string) error {
-	_, err := sg.GetService(name)
+	svc, err := sg.GetService(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = sg.runPhase(ctx, svc, EventRestart, func(ctx context.Context) error {
+		// 只有运行中的服务需要先停止，处于 Error 状态的服务会在启动时重新初始化
+		if isActive(svc.State()) {
+			if err := sg.stopService(ctx, name); err != nil {
+				return err
+			}
+		}
+		return sg.startService(ctx, name)
+	})
+	sg.metrics.RecordRestart(name)
+	return err
+}
+
+// StartService 启动指定服务，服务的依赖必须已经就绪
+func (sg *ServiceGroup) StartService(ctx context.Context, name string) error {
+	if _, err := sg.GetService(name); err != nil {
+		return err
+	}
+	return sg.startWhenReady(ctx, name)
+}
+
+// StopService 停止指定服务
+func (sg *ServiceGroup) StopService(ctx context.Context, name string) error {
+	return sg.stopService(ctx, name)
+}
+
+// StartOnly 只启动指定的服务及其运行所需的所有服务（直接或间接的必需依赖和可选依赖），已在运行的服务会被跳过
+// 与 Start 不同，StartOnly 不要求整个依赖图完整，也不会启动健康检查循环，适用于在集成测试中运行部分服务
+func (sg *ServiceGroup) StartOnly(names ...string) error {
+	targets := make(map[string]bool)
+	for _, name := range names {
+		if _, err := sg.GetService(name); err != nil {
+			return err
+		}
+		targets[name] = true
+		for _, dep := range sg.depGraph.TransitiveDependencies(name, DependencyRequired, DependencyOptional) {
+			targets[dep] = true
+		}
+	}
+
+	// 检查需要启动的服务的必需依赖是否完整
+	var chains []string
+	for name := range targets {
+		deps, _ := sg.depGraph.GetDependencies(name)
+		for _, dep := range deps {
+			if _, ok := sg.services.Load(dep); !ok {
+				chains = append(chains, name+" -> "+dep)
+			}
+		}
+	}
+	if len(chains) > 0 {
+		sort.Strings(chains)
+		return &ServiceError{
+			Code:    ErrDependencyFailed,
+			Message: "missing required dependency: " + strings.Join(chains, "; "),
+		}
+	}
+
+	// 按子图的层级启动，跳过已在运行的服务
+	subset := make([]string, 0, len(targets))
+	for name := range targets {
+		subset = append(subset, name)
+	}
+	levels, err := sg.depGraph.Subgraph(subset...).GetStartLevels()
+	if err != nil {
+		return err
+	}
+	pending := sg.inactiveLevels(levels)
+
+	ctx, cancel := context.WithTimeout(sg.ctx, sg.options.StartTimeout)
+	defer cancel()
+
+	ctx, span := sg.tracer.Start(ctx, "ServiceGroup.StartOnly", WithSpanAttributes(
+		"group", sg.options.Name,
+		"targets", names,
+		"services", len(subset)))
+	defer span.End()
+
+	sg.log.Info("Starting services",
+		"targets", names,
+		"services", len(subset),
+		"levels", len(pending))
+
+	report, err := sg.startLevels(ctx, pending)
+	if err != nil {
+		span.RecordError(err)
+		sg.log.Error("Services failed to start",
+			"targets", names,
+			"duration", report.Duration,
+			"error", err)
+		return err
+	}
+	sg.log.Info("Services started",
+		"targets", names,
+		"duration", report.Duration,
+		"critical_chain", strings.Join(report.CriticalPath, " -> "))
+	return nil
+}
+
+// StopWithDependents 停止指定的服务以及直接或间接（通过必需或可选依赖）依赖它们的所有服务，依赖方先于依赖停止
+// 只依赖它们的弱依赖或顺序提示的服务不会被停止
+func (sg *ServiceGroup) StopWithDependents(ctx context.Context, names ...string) error {
+	targets := make(map[string]bool)
+	for _, name := range names {
+		if _, err := sg.GetService(name); err != nil {
+			return err
+		}
+		targets[name] = true
+		for _, d := range sg.depGraph.TransitiveDependents(name, DependencyRequired, DependencyOptional) {
+			targets[d] = true
+		}
+	}
+
+	subset := make([]string, 0, len(targets))
+	for name := range targets {
+		subset = append(subset, name)
+	}
+	levels, err := sg.depGraph.Subgraph(subset...).GetStartLevels()
 	if err != nil {
 		return err
 	}
 
-	if err := sg.stopService(ctx, name); err != nil {
+	ctx, cancel := context.WithTimeout(ctx, sg.options.StopTimeout)
+	defer cancel()
+
+	ctx, span := sg.tracer.Start(ctx, "ServiceGroup.StopWithDependents", WithSpanAttributes(
+		"group", sg.options.Name,
+		"targets", names,
+		"services", len(subset)))
+	defer span.End()
+
+	sg.log.Info("Stopping services with dependents",
+		"targets", names,
+		"services", len(subset))
+
+	if err := sg.stopLevels(ctx, levels); err != nil {
+		span.RecordError(err)
 		return err
 	}
+	return nil
+}
 
-	return sg.startService(ctx, name)
+// DependencyGraph 获取服务组的依赖图
+func (sg *ServiceGroup) DependencyGraph() *DependencyGraph {
+	return sg.depGraph
 }
 
 // ListServices 列出所有服务
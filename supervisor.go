@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RestartPolicy 服务重启策略
+type RestartPolicy int
+
+const (
+	// RestartNever 从不自动重启（默认）
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure 仅在服务失败（进入 Error 状态或健康检查失败）时重启
+	RestartOnFailure
+	// RestartAlways 服务失败或意外停止时都会重启，通过 StopService 等方式主动停止的服务不会被重启
+	RestartAlways
+)
+
+// String 实现 Stringer 接口
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("RestartPolicy(%d)", int(p))
+	}
+}
+
+// RestartStrategy 重启范围策略
+type RestartStrategy int
+
+const (
+	// OneForOne 只重启失败的服务
+	OneForOne RestartStrategy = iota
+	// OneForAll 重启组内所有服务
+	OneForAll
+	// RestForOne 重启失败的服务以及启动顺序在它之后的所有服务
+	RestForOne
+)
+
+// String 实现 Stringer 接口
+func (s RestartStrategy) String() string {
+	switch s {
+	case OneForOne:
+		return "one-for-one"
+	case OneForAll:
+		return "one-for-all"
+	case RestForOne:
+		return "rest-for-one"
+	default:
+		return fmt.Sprintf("RestartStrategy(%d)", int(s))
+	}
+}
+
+// SupervisorOptions 监督器配置
+type SupervisorOptions struct {
+	Policy         RestartPolicy   // 默认重启策略
+	Strategy       RestartStrategy // 重启范围
+	MaxRestarts    int             // 时间窗口内允许的最大重启次数，超过后停止整个服务组
+	Window         time.Duration   // 重启次数统计窗口
+	InitialBackoff time.Duration   // 首次重启前的等待时间
+	MaxBackoff     time.Duration   // 重启等待时间上限
+}
+
+// DefaultSupervisorOptions 默认监督器配置
+var DefaultSupervisorOptions = SupervisorOptions{
+	Policy:         RestartNever,
+	Strategy:       OneForOne,
+	MaxRestarts:    5,
+	Window:         time.Minute,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Second * 30,
+}
+
+// normalize 用默认值补全未设置的字段
+func (o SupervisorOptions) normalize() SupervisorOptions {
+	if o.MaxRestarts <= 0 {
+		o.MaxRestarts = DefaultSupervisorOptions.MaxRestarts
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultSupervisorOptions.Window
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultSupervisorOptions.InitialBackoff
+	}
+	if o.MaxBackoff < o.InitialBackoff {
+		o.MaxBackoff = max(DefaultSupervisorOptions.MaxBackoff, o.InitialBackoff)
+	}
+	return o
+}
+
+// supervisor 根据重启策略监督服务组内的服务
+type supervisor struct {
+	sg   *ServiceGroup
+	opts SupervisorOptions
+
+	mu        sync.Mutex
+	policies  map[string]RestartPolicy
+	backoff   map[string]time.Duration
+	busy      map[string]bool
+	stopped   map[string]bool // 由服务组主动停止的服务，不视为意外退出
+	restarts  []time.Time
+	watching  bool // Start 成功后开始监督，启动期间的失败由 Start 自身报告
+	escalated bool
+	err       error         // 升级停止服务组的原因
+	halted    chan struct{} // 升级后停止服务组完成时关闭
+}
+
+// newSupervisor 创建监督器
+func newSupervisor(sg *ServiceGroup, opts SupervisorOptions) *supervisor {
+	return &supervisor{
+		sg:       sg,
+		opts:     opts.normalize(),
+		policies: make(map[string]RestartPolicy),
+		backoff:  make(map[string]time.Duration),
+		busy:     make(map[string]bool),
+		stopped:  make(map[string]bool),
+		halted:   make(chan struct{}),
+	}
+}
+
+// policyFor 获取服务的重启策略
+func (sv *supervisor) policyFor(name string) RestartPolicy {
+	if p, ok := sv.policies[name]; ok {
+		return p
+	}
+	return sv.opts.Policy
+}
+
+// watch 开始监督服务组内的服务
+func (sv *supervisor) watch() {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	sv.watching = true
+}
+
+// observe 根据服务的当前状态和一次健康检查的结果决定是否需要重启服务
+// 由健康检查循环和服务的状态变更（进入 Error 或 Stopped 状态）触发，checkErr 为存活探针的错误
+func (sv *supervisor) observe(s Service, checkErr error) {
+	name := s.Name()
+	state := s.State()
+
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	if !sv.watching || sv.escalated || sv.busy[name] || sv.sg.ctx.Err() != nil {
+		return
+	}
+
+	failed := state == StateError || (state == StateRunning && checkErr != nil)
+	exited := state == StateStopped && !sv.stopped[name]
+
+	if !failed && !exited {
+		// 服务健康，重置退避时间
+		if state == StateRunning {
+			delete(sv.backoff, name)
+		}
+		return
+	}
+
+	policy := sv.policyFor(name)
+	switch {
+	case failed && policy != RestartNever:
+	case exited && policy == RestartAlways:
+	default:
+		return
+	}
+
+	cause := checkErr
+	if cause == nil {
+		cause = &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("service %s is in state %s", name, state),
+		}
+	}
+
+	// 检查重启强度
+	now := time.Now()
+	cutoff := now.Add(-sv.opts.Window)
+	kept := sv.restarts[:0]
+	for _, t := range sv.restarts {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	sv.restarts = append(kept, now)
+	if len(sv.restarts) > sv.opts.MaxRestarts {
+		sv.escalated = true
+		go sv.escalate(name, cause)
+		return
+	}
+
+	delay := sv.backoff[name]
+	if delay == 0 {
+		delay = sv.opts.InitialBackoff
+	} else {
+		delay = min(delay*2, sv.opts.MaxBackoff)
+	}
+	sv.backoff[name] = delay
+
+	targets := sv.targets(name)
+	for _, t := range targets {
+		sv.busy[t] = true
+	}
+	go sv.restart(name, targets, delay, cause)
+}
+
+// setStopped 记录服务是否由服务组主动停止（StopService、StopWithDependents、Remove 等），启动时清除
+func (sv *supervisor) setStopped(name string, stopped bool) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	if stopped {
+		sv.stopped[name] = true
+	} else {
+		delete(sv.stopped, name)
+	}
+}
+
+// targets 根据重启范围策略计算需要重启的服务（按启动顺序）
+func (sv *supervisor) targets(name string) []string {
+	if sv.opts.Strategy == OneForOne {
+		return []string{name}
+	}
+
+	order, err := sv.sg.depGraph.GetStartOrder()
+	if err != nil {
+		return []string{name}
+	}
+	if sv.opts.Strategy == OneForAll {
+		return order
+	}
+	for i, n := range order {
+		if n == name {
+			return order[i:]
+		}
+	}
+	return []string{name}
+}
+
+// restart 等待退避时间后按策略重启服务
+func (sv *supervisor) restart(trigger string, targets []string, delay time.Duration, cause error) {
+	defer func() {
+		sv.mu.Lock()
+		for _, t := range targets {
+			delete(sv.busy, t)
+		}
+		sv.mu.Unlock()
+
+		// 重启失败的服务不会再发生状态变更，立即按退避时间安排下一次重启
+		for _, t := range targets {
+			if svc, err := sv.sg.GetService(t); err == nil && svc.State() == StateError {
+				sv.observe(svc, nil)
+			}
+		}
+	}()
+
+	sv.sg.log.Warn("Restarting service",
+		"service", trigger,
+		"strategy", sv.opts.Strategy,
+		"delay", delay,
+		"error", cause)
+
+	select {
+	case <-sv.sg.ctx.Done():
+		return
+	case <-time.After(delay):
+	}
+
+	ctx, cancel := context.WithTimeout(sv.sg.ctx, sv.sg.options.StartTimeout)
+	defer cancel()
+
+	ctx, span := sv.sg.tracer.Start(ctx, "Supervisor.Restart", WithSpanAttributes(
+		"service", trigger,
+		"strategy", sv.opts.Strategy.String(),
+		"services", targets))
+	defer span.End()
+
+	// 只重启触发重启的服务和此前处于活动状态的服务，
+	// 主动停止的服务和从未启动过的服务（例如 StartOnly 之外的服务）保持原状
+	active := sv.activeTargets(trigger, targets)
+
+	// 逆序停止仍在运行的服务，重启过程中的停止不视为主动停止
+	for i := len(targets) - 1; i >= 0; i-- {
+		name := targets[i]
+		if !active[name] {
+			continue
+		}
+		svc, err := sv.sg.GetService(name)
+		if err != nil || svc.State() != StateRunning {
+			continue
+		}
+		if err := sv.sg.stopService(ctx, name); err != nil {
+			sv.sg.log.Error("Error stopping service for restart",
+				"service", name,
+				"error", err)
+		}
+		sv.setStopped(name, false)
+	}
+
+	// 按启动顺序重新启动
+	for _, name := range targets {
+		if !active[name] {
+			continue
+		}
+		err := sv.sg.startService(ctx, name)
+		if err == nil {
+			sv.sg.metrics.RecordRestart(name)
+		}
+
+		event := ServiceEvent{
+			ServiceName: name,
+			EventType:   EventRestart,
+			Time:        time.Now(),
+			Error:       err,
+			Metadata: map[string]interface{}{
+				"trigger":  trigger,
+				"strategy": sv.opts.Strategy.String(),
+				"cause":    cause,
+			},
+		}
+		if svc, lookupErr := sv.sg.GetService(name); lookupErr == nil {
+			event.State = svc.State()
+		}
+		sv.sg.events.PublishEvent(event)
+
+		if err != nil {
+			span.RecordError(err)
+			sv.sg.log.Error("Failed to restart service",
+				"service", name,
+				"error", err)
+			return
+		}
+	}
+}
+
+// activeTargets 返回需要重启的服务：触发重启的服务，以及处于 Starting 或 Running 状态且未被主动停止的服务
+func (sv *supervisor) activeTargets(trigger string, targets []string) map[string]bool {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	active := map[string]bool{trigger: true}
+	for _, name := range targets {
+		if sv.stopped[name] {
+			continue
+		}
+		if svc, err := sv.sg.GetService(name); err == nil && isActive(svc.State()) {
+			active[name] = true
+		}
+	}
+	return active
+}
+
+// escalate 重启次数超过限制，停止整个服务组
+func (sv *supervisor) escalate(name string, cause error) {
+	defer close(sv.halted)
+
+	err := &ServiceError{
+		Code: ErrStartupFailed,
+		Message: fmt.Sprintf("restart intensity exceeded (%d restarts in %s), stopping service group",
+			sv.opts.MaxRestarts, sv.opts.Window),
+		Err: cause,
+	}
+
+	sv.mu.Lock()
+	sv.err = err
+	sv.mu.Unlock()
+
+	sv.sg.log.Error("Supervisor escalation",
+		"service", name,
+		"error", err)
+
+	sv.sg.events.PublishEvent(ServiceEvent{
+		ServiceName: name,
+		EventType:   EventError,
+		State:       StateError,
+		Time:        time.Now(),
+		Error:       err,
+		Metadata: map[string]interface{}{
+			"escalated": true,
+		},
+	})
+
+	if stopErr := sv.sg.Stop(); stopErr != nil {
+		sv.sg.log.Error("Error stopping service group after escalation",
+			"error", stopErr)
+	}
+}
+
+// escalation 返回监督器停止服务组的原因，未发生时返回 nil
+func (sv *supervisor) escalation() error {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	return sv.err
+}
+
+// waitHalted 等待升级引发的服务组停止完成，只能在 escalation 返回非 nil 后调用
+func (sv *supervisor) waitHalted() {
+	<-sv.halted
+}
+
+// SetRestartPolicy 为指定服务设置重启策略，覆盖默认策略
+func (sg *ServiceGroup) SetRestartPolicy(name string, policy RestartPolicy) error {
+	if _, err := sg.GetService(name); err != nil {
+		return err
+	}
+
+	sg.supervisor.mu.Lock()
+	defer sg.supervisor.mu.Unlock()
+	sg.supervisor.policies[name] = policy
+	return nil
+}
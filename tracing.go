@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID 追踪标识，同一次 Start/Stop 产生的所有 span 共享同一个 TraceID
+type TraceID uint64
+
+// String 返回 16 位十六进制表示
+func (id TraceID) String() string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+// SpanID span 标识
+type SpanID uint64
+
+// String 返回 16 位十六进制表示
+func (id SpanID) String() string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+// SpanContext 标识一个 span，用于父子关系和链接
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid 是否为有效的 span 标识
+func (sc SpanContext) IsValid() bool {
+	return sc.SpanID != 0
+}
+
+// Span 一次被追踪的操作
+type Span interface {
+	// SpanContext 返回 span 的标识
+	SpanContext() SpanContext
+	// SetAttribute 设置属性
+	SetAttribute(key string, value interface{})
+	// AddLink 链接到另一个 span，例如服务启动前等待的依赖
+	AddLink(link SpanContext)
+	// RecordError 记录操作失败的错误
+	RecordError(err error)
+	// End 结束 span
+	End()
+}
+
+// Tracer 创建 span，可以适配 OpenTelemetry 等追踪系统
+type Tracer interface {
+	// Start 以 ctx 中的 span 为父 span 创建新的 span，返回携带新 span 的上下文
+	Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span)
+}
+
+// SpanConfig 创建 span 时的配置
+type SpanConfig struct {
+	Attributes map[string]interface{}
+	Links      []SpanContext
+}
+
+// SpanOption 创建 span 的选项
+type SpanOption func(*SpanConfig)
+
+// WithSpanAttributes 设置 span 的初始属性，参数为交替的键值对
+func WithSpanAttributes(kv ...interface{}) SpanOption {
+	return func(c *SpanConfig) {
+		if c.Attributes == nil {
+			c.Attributes = make(map[string]interface{}, len(kv)/2)
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			c.Attributes[fmt.Sprint(kv[i])] = kv[i+1]
+		}
+	}
+}
+
+// WithLinks 设置 span 的链接
+func WithLinks(links ...SpanContext) SpanOption {
+	return func(c *SpanConfig) {
+		for _, link := range links {
+			if link.IsValid() {
+				c.Links = append(c.Links, link)
+			}
+		}
+	}
+}
+
+// NewSpanConfig 应用选项并返回 span 配置，供 Tracer 实现使用
+func NewSpanConfig(opts ...SpanOption) SpanConfig {
+	var c SpanConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// spanKey 上下文中保存当前 span 的键
+type spanKey struct{}
+
+// ContextWithSpan 返回携带 span 的上下文
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext 返回上下文中的当前 span，没有时返回不记录任何内容的 span
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// noopTracer 未配置 Tracer 时使用，不记录任何内容
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...SpanOption) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// noopSpan 不记录任何内容的 span
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext         { return SpanContext{} }
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) AddLink(SpanContext)              {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+// SpanData 已结束的 span 的记录
+type SpanData struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext // 根 span 的 Parent 无效
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes map[string]interface{}
+	Links      []SpanContext
+	Err        error
+}
+
+// Duration 返回 span 的耗时
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// MemoryTracer 将 span 保存在内存中的 Tracer，适用于测试和诊断
+type MemoryTracer struct {
+	mu     sync.Mutex
+	nextID atomic.Uint64
+	spans  []SpanData
+}
+
+// NewMemoryTracer 创建内存 Tracer
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// Start 实现 Tracer 接口
+func (t *MemoryTracer) Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	config := NewSpanConfig(opts...)
+
+	parent := SpanFromContext(ctx).SpanContext()
+	sc := SpanContext{
+		TraceID: parent.TraceID,
+		SpanID:  SpanID(t.nextID.Add(1)),
+	}
+	if !parent.IsValid() {
+		sc.TraceID = TraceID(sc.SpanID)
+	}
+
+	attrs := make(map[string]interface{}, len(config.Attributes))
+	for k, v := range config.Attributes {
+		attrs[k] = v
+	}
+
+	span := &memorySpan{
+		tracer: t,
+		data: SpanData{
+			Name:       name,
+			Context:    sc,
+			Parent:     parent,
+			StartTime:  time.Now(),
+			Attributes: attrs,
+			Links:      config.Links,
+		},
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Spans 按结束顺序返回所有已结束的 span
+func (t *MemoryTracer) Spans() []SpanData {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SpanData(nil), t.spans...)
+}
+
+// Reset 清空已记录的 span
+func (t *MemoryTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+// memorySpan MemoryTracer 创建的 span
+type memorySpan struct {
+	tracer *MemoryTracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+func (s *memorySpan) SpanContext() SpanContext {
+	return s.data.Context
+}
+
+func (s *memorySpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *memorySpan) AddLink(link SpanContext) {
+	if !link.IsValid() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Links = append(s.data.Links, link)
+}
+
+func (s *memorySpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+func (s *memorySpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.spans = append(s.tracer.spans, data)
+}
+
+// spanIndex 记录每个服务最近一次 Start 和 Stop 步骤的 span，用于把服务的 span 链接到它等待过的服务
+type spanIndex struct {
+	mu    sync.Mutex
+	start map[string]SpanContext
+	stop  map[string]SpanContext
+}
+
+// newSpanIndex 创建 span 索引
+func newSpanIndex() *spanIndex {
+	return &spanIndex{
+		start: make(map[string]SpanContext),
+		stop:  make(map[string]SpanContext),
+	}
+}
+
+// setStart 记录服务的 Start 步骤 span
+func (si *spanIndex) setStart(name string, sc SpanContext) {
+	si.set(si.start, name, sc)
+}
+
+// setStop 记录服务的 Stop 步骤 span
+func (si *spanIndex) setStop(name string, sc SpanContext) {
+	si.set(si.stop, name, sc)
+}
+
+func (si *spanIndex) set(m map[string]SpanContext, name string, sc SpanContext) {
+	if !sc.IsValid() {
+		return
+	}
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	m[name] = sc
+}
+
+// startLinks 返回指定服务在同一追踪中的 Start 步骤 span
+func (si *spanIndex) startLinks(ctx context.Context, names []string) []SpanContext {
+	return si.links(ctx, si.start, names)
+}
+
+// stopLinks 返回指定服务在同一追踪中的 Stop 步骤 span
+func (si *spanIndex) stopLinks(ctx context.Context, names []string) []SpanContext {
+	return si.links(ctx, si.stop, names)
+}
+
+func (si *spanIndex) links(ctx context.Context, m map[string]SpanContext, names []string) []SpanContext {
+	trace := SpanFromContext(ctx).SpanContext()
+	if !trace.IsValid() {
+		return nil
+	}
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	links := make([]SpanContext, 0, len(names))
+	for _, name := range names {
+		// 只链接同一次启动或停止中的 span，避免指向上一次运行留下的记录
+		if sc, ok := m[name]; ok && sc.TraceID == trace.TraceID {
+			links = append(links, sc)
+		}
+	}
+	return links
+}
+
+// delete 移除服务的 span 记录
+func (si *spanIndex) delete(name string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	delete(si.start, name)
+	delete(si.stop, name)
+}
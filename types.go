@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 )
 
 // ServiceState 定义服务状态
@@ -30,11 +32,28 @@ func (s ServiceState) String() string {
 	}[s]
 }
 
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (s ServiceState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口
+func (s *ServiceState) UnmarshalText(text []byte) error {
+	for state := StateUninitialized; state <= StateError; state++ {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown service state: %s", text)
+}
+
 // ServiceError 定义统一的错误类型
 type ServiceError struct {
 	Code    ErrorCode
 	Message string
 	Err     error
+	Cycle   []string // 循环依赖的完整路径，首尾为同一个服务，例如 [api cache auth api]
 }
 
 // ErrorCode 定义错误码
@@ -50,6 +69,7 @@ const (
 	ErrShutdownTimeout
 	ErrShutdownFailed
 	ErrDependencyFailed
+	ErrInvalidManifest
 )
 
 // Error 实现 error 接口
@@ -72,6 +92,7 @@ func (e ErrorCode) String() string {
 		"ShutdownTimeout",
 		"ShutdownFailed",
 		"DependencyFailed",
+		"InvalidManifest",
 	}[e]
 }
 
@@ -111,3 +132,36 @@ type Service interface {
 	// Priority 返回服务优先级
 	Priority() ServicePriority
 }
+
+// StopTimeouter 可选接口，服务可以声明自己的停止期限
+type StopTimeouter interface {
+	StopTimeout() time.Duration
+}
+
+// SoftDependencies 可选接口，声明必需依赖之外的依赖
+type SoftDependencies interface {
+	// OptionalDependencies 可选依赖，不存在时忽略
+	OptionalDependencies() []string
+	// WeakDependencies 弱依赖，只影响启动顺序，失败不阻塞
+	WeakDependencies() []string
+	// AfterDependencies 启动顺序提示
+	AfterDependencies() []string
+}
+
+// StateChange 服务状态变更信息
+type StateChange struct {
+	Service  string
+	From     ServiceState
+	To       ServiceState
+	Time     time.Time
+	Duration time.Duration // 在 From 状态停留的时间
+}
+
+// StateObserver 状态变更观察者
+type StateObserver func(change StateChange)
+
+// StateNotifier 可选接口，服务可以主动报告状态机的每一次状态变更
+// 服务组会为实现该接口的服务注册观察者，将状态变更转发到事件总线，服务被移除时调用返回的函数注销观察者
+type StateNotifier interface {
+	AddStateObserver(observer StateObserver) (remove func())
+}